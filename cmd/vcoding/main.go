@@ -1,13 +1,35 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/futureCreator/vcoding/internal/cli"
 )
 
 func main() {
-	if err := cli.Execute(); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first SIGINT/SIGTERM cancels the command context so the running
+	// pipeline can record its state; a second one exits immediately.
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		fmt.Fprintln(os.Stderr, "\nInterrupted; saving run state. Press Ctrl-C again to force exit.")
+		cancel()
+		<-sigs
+		os.Exit(130)
+	}()
+
+	if err := cli.Execute(ctx); err != nil {
+		if ctx.Err() != nil {
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }
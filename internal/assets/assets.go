@@ -9,10 +9,12 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
 )
 
-//go:embed prompts/*.md
+//go:embed prompts/*.md prompts/partials/*.md
 var promptsFS embed.FS
 
 //go:embed pipelines/*.yaml
@@ -42,6 +44,179 @@ func AllPrompts() (map[string]string, error) {
 	return readAll(promptsFS, "prompts", ".md")
 }
 
+// AllPartials returns all embedded prompt partials as a map (name → content).
+// Partials are shared snippets that prompts include with {{template "name" .}}.
+func AllPartials() (map[string]string, error) {
+	return readAll(promptsFS, "prompts/partials", ".md")
+}
+
+// Prompt layers, from lowest to highest priority.
+const (
+	LayerEmbedded = "embedded"
+	LayerUser     = "user"
+	LayerProject  = "project"
+)
+
+// PromptEntry is a prompt template or partial resolved from one of the prompt layers.
+type PromptEntry struct {
+	Name    string
+	Content string
+	Layer   string // LayerEmbedded | LayerUser | LayerProject
+	Path    string // source file path; embedded entries use their path within the binary
+	Partial bool
+}
+
+// PromptRegistry merges prompts and partials from all layers.
+// Higher layers replace entries of the same name and may add new names.
+type PromptRegistry struct {
+	Prompts  map[string]PromptEntry
+	Partials map[string]PromptEntry
+}
+
+// LoadPromptRegistry builds the prompt registry from embedded defaults,
+// user ~/.vcoding/prompts/ and project .vcoding/prompts/, in that order.
+func LoadPromptRegistry() (*PromptRegistry, error) {
+	reg := &PromptRegistry{
+		Prompts:  map[string]PromptEntry{},
+		Partials: map[string]PromptEntry{},
+	}
+
+	if err := reg.addEmbedded("prompts", false); err != nil {
+		return nil, err
+	}
+	if err := reg.addEmbedded("prompts/partials", true); err != nil {
+		return nil, err
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if err := reg.addDir(filepath.Join(home, ".vcoding", "prompts"), LayerUser); err != nil {
+			return nil, err
+		}
+	}
+	if err := reg.addDir(filepath.Join(".vcoding", "prompts"), LayerProject); err != nil {
+		return nil, err
+	}
+
+	return reg, nil
+}
+
+func (r *PromptRegistry) addEmbedded(dir string, partial bool) error {
+	entries, err := readAll(promptsFS, dir, ".md")
+	if err != nil {
+		return err
+	}
+	for name, content := range entries {
+		r.add(PromptEntry{
+			Name:    name,
+			Content: content,
+			Layer:   LayerEmbedded,
+			Path:    dir + "/" + name + ".md",
+			Partial: partial,
+		})
+	}
+	return nil
+}
+
+// addDir adds *.md prompts from dir and partials from dir/partials.
+// A missing directory is not an error.
+func (r *PromptRegistry) addDir(dir, layer string) error {
+	for _, sub := range []struct {
+		path    string
+		partial bool
+	}{{dir, false}, {filepath.Join(dir, "partials"), true}} {
+		entries, err := os.ReadDir(sub.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("reading prompt dir %s: %w", sub.path, err)
+		}
+		for _, e := range entries {
+			if e.IsDir() || filepath.Ext(e.Name()) != ".md" {
+				continue
+			}
+			path := filepath.Join(sub.path, e.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("reading prompt %s: %w", path, err)
+			}
+			r.add(PromptEntry{
+				Name:    strings.TrimSuffix(e.Name(), ".md"),
+				Content: string(data),
+				Layer:   layer,
+				Path:    path,
+				Partial: sub.partial,
+			})
+		}
+	}
+	return nil
+}
+
+func (r *PromptRegistry) add(e PromptEntry) {
+	if e.Partial {
+		r.Partials[e.Name] = e
+	} else {
+		r.Prompts[e.Name] = e
+	}
+}
+
+// Lookup returns the effective prompt or partial with the given name.
+// Prompts take precedence over partials.
+func (r *PromptRegistry) Lookup(name string) (PromptEntry, bool) {
+	if e, ok := r.Prompts[name]; ok {
+		return e, true
+	}
+	e, ok := r.Partials[name]
+	return e, ok
+}
+
+// Entries returns all prompts followed by all partials, each sorted by name.
+func (r *PromptRegistry) Entries() []PromptEntry {
+	var result []PromptEntry
+	for _, m := range []map[string]PromptEntry{r.Prompts, r.Partials} {
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			result = append(result, m[name])
+		}
+	}
+	return result
+}
+
+// Templates returns the effective prompt contents (name → content).
+func (r *PromptRegistry) Templates() map[string]string {
+	return contents(r.Prompts)
+}
+
+// PartialTemplates returns the effective partial contents (name → content).
+func (r *PromptRegistry) PartialTemplates() map[string]string {
+	return contents(r.Partials)
+}
+
+func contents(m map[string]PromptEntry) map[string]string {
+	result := make(map[string]string, len(m))
+	for name, e := range m {
+		result[name] = e.Content
+	}
+	return result
+}
+
+// EmbeddedPrompt returns the built-in default for a prompt or partial.
+func EmbeddedPrompt(name string, partial bool) (string, error) {
+	dir := "prompts"
+	if partial {
+		dir = "prompts/partials"
+	}
+	data, err := promptsFS.ReadFile(dir + "/" + name + ".md")
+	if err != nil {
+		return "", fmt.Errorf("no built-in prompt %q", name)
+	}
+	return string(data), nil
+}
+
 func loadWithOverride(dir, filename string, embedded embed.FS) (string, error) {
 	// 1. project-level override
 	projectPath := filepath.Join(".vcoding", dir, filename)
@@ -7,8 +7,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var askPipeline string
-var askVerbose bool
+var askOpts runOptions
 
 var askCmd = &cobra.Command{
 	Use:          "ask <message>",
@@ -20,12 +19,11 @@ var askCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		prompt := strings.Join(args, " ")
 		src := &source.PromptSource{Prompt: prompt}
-		return runPipeline(cmd.Context(), src, askPipeline, askVerbose)
+		return runPipeline(cmd.Context(), src, askOpts)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(askCmd)
-	askCmd.Flags().StringVarP(&askPipeline, "pipeline", "p", "default", "Pipeline to use")
-	askCmd.Flags().BoolVarP(&askVerbose, "verbose", "v", false, "Stream executor output to terminal")
+	addRunFlags(askCmd, &askOpts)
 }
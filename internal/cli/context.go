@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/futureCreator/vcoding/internal/config"
+	"github.com/futureCreator/vcoding/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var contextExplain []string
+var contextScope string
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "List files eligible for the project context",
+	Long: `List the files eligible for project:context under the project_context
+settings, before ranking and budgets are applied.
+
+With --explain, show for each path whether it is eligible and which include or
+exclude pattern, ignore file or limit decided it.`,
+	Example:      "vcoding context\nvcoding context --explain internal/cli/runner.go --explain docs/generated",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runContext,
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.Flags().StringArrayVar(&contextExplain, "explain", nil, "Explain why a path is included or excluded (repeatable)")
+	contextCmd.Flags().StringVar(&contextScope, "scope", "", "Limit the project context to a subdirectory")
+}
+
+func runContext(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadScope(contextScope)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	pc := &cfg.ProjectContext
+
+	if len(contextExplain) > 0 {
+		for _, p := range contextExplain {
+			e, err := project.Explain(pc, p)
+			if err != nil {
+				return err
+			}
+			verdict := "excluded"
+			if e.Eligible {
+				verdict = "included"
+			}
+			fmt.Printf("%s: %s — %s\n", e.Path, verdict, e.Reason)
+		}
+		return nil
+	}
+
+	files, err := project.Collect(pc)
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, f := range files {
+		tokens := len(f.Content) / 4
+		total += tokens
+		fmt.Printf("%8d  %s\n", tokens, f.Path)
+	}
+	fmt.Printf("\n%d files, ~%d tokens (max_files %d, max_tokens %d)\n", len(files), total, pc.MaxFiles, pc.MaxTokens)
+	return nil
+}
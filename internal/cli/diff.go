@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/futureCreator/vcoding/internal/diff"
+	"github.com/futureCreator/vcoding/internal/run"
+	"github.com/spf13/cobra"
+)
+
+var diffFrom string
+var diffTo string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <run-id> <artifact>",
+	Short: "Show changes between versions of a run artifact",
+	Long: `Show a unified diff between two versions of an artifact in a run.
+
+Versions are selected by the step that produced them (e.g. Plan, Revise) or by
+1-based version number. By default the first and the last version are compared.`,
+	Example:      "vcoding diff latest PLAN.md\nvcoding diff latest PLAN.md --from Plan --to Revise",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE:         runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVar(&diffFrom, "from", "", "Old version (step name or version number)")
+	diffCmd.Flags().StringVar(&diffTo, "to", "", "New version (step name or version number)")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	r, err := run.Open(args[0])
+	if err != nil {
+		return err
+	}
+	name := args[1]
+	versions := r.ArtifactVersions(name)
+	if len(versions) == 0 {
+		return fmt.Errorf("run %s has no recorded versions of %s", r.ID, name)
+	}
+	if len(versions) == 1 && diffFrom == "" && diffTo == "" {
+		fmt.Printf("%s has a single version (produced by %s).\n", name, stepLabel(versions[0]))
+		return nil
+	}
+
+	from, err := selectVersion(versions, diffFrom, 0)
+	if err != nil {
+		return err
+	}
+	to, err := selectVersion(versions, diffTo, len(versions)-1)
+	if err != nil {
+		return err
+	}
+
+	oldContent, err := r.ReadFile(from.File)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", from.File, err)
+	}
+	newContent, err := r.ReadFile(to.File)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", to.File, err)
+	}
+
+	out := diff.Unified(from.File, to.File, oldContent, newContent, diff.DefaultContext)
+	if out == "" {
+		fmt.Printf("No differences between %s and %s.\n", from.File, to.File)
+		return nil
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// selectVersion finds a version by step name or 1-based index.
+// An empty selector returns the version at index def.
+func selectVersion(versions []run.ArtifactVersion, sel string, def int) (run.ArtifactVersion, error) {
+	if sel == "" {
+		return versions[def], nil
+	}
+	if n, err := strconv.Atoi(sel); err == nil {
+		if n < 1 || n > len(versions) {
+			return run.ArtifactVersion{}, fmt.Errorf("version %d out of range (1-%d)", n, len(versions))
+		}
+		return versions[n-1], nil
+	}
+	// Prefer the latest version produced by the step.
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Step == sel {
+			return versions[i], nil
+		}
+	}
+	return run.ArtifactVersion{}, fmt.Errorf("no version produced by step %q", sel)
+}
+
+func stepLabel(v run.ArtifactVersion) string {
+	if v.Step == "" {
+		return "input"
+	}
+	return "step " + v.Step
+}
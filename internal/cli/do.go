@@ -5,8 +5,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var doPipeline string
-var doVerbose bool
+var doOpts runOptions
 
 var doCmd = &cobra.Command{
 	Use:          "do <spec-file>",
@@ -15,11 +14,10 @@ var doCmd = &cobra.Command{
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		src := &source.SpecSource{Path: args[0]}
-		return runPipeline(cmd.Context(), src, doPipeline, doVerbose)
+		return runPipeline(cmd.Context(), src, doOpts)
 	},
 }
 
 func init() {
-	doCmd.Flags().StringVarP(&doPipeline, "pipeline", "p", "default", "Pipeline to use")
-	doCmd.Flags().BoolVarP(&doVerbose, "verbose", "v", false, "Stream executor output to terminal")
+	addRunFlags(doCmd, &doOpts)
 }
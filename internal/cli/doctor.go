@@ -2,10 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/futureCreator/vcoding/internal/config"
+	"github.com/futureCreator/vcoding/internal/project"
 	"github.com/spf13/cobra"
 )
 
@@ -20,7 +24,7 @@ func init() {
 }
 
 func runDoctor(cmd *cobra.Command, args []string) error {
-	allOK := runChecks(true, true)
+	allOK := runChecks(checkOptions{verbose: true, config: true, apiKey: true, trackers: configuredTrackers()})
 	fmt.Println()
 	if allOK {
 		fmt.Println("All checks passed. vcoding is ready.")
@@ -33,36 +37,80 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 // checkGitAndGH runs only git and gh checks (no config).
 // Used by init, which creates the config and doesn't require it upfront.
 func checkGitAndGH() error {
-	if !runChecks(false, false) {
+	if !runChecks(checkOptions{trackers: []string{"github"}}) {
 		return fmt.Errorf("prerequisite checks failed; run `vcoding doctor` for details")
 	}
 	return nil
 }
 
-// checkPrerequisites runs all checks including config and API key.
-// Used by pick/do before starting a pipeline run.
-func checkPrerequisites() error {
-	if !runChecks(false, true) {
+// checkPrerequisites runs the checks for a pipeline run that fetches its
+// input from tracker ("" for spec files and messages): git, config, the API
+// key unless apiKey is false, and access to the tracker. Failed checks are
+// printed to w.
+// Used by pick/do/ask before starting a pipeline run.
+func checkPrerequisites(w io.Writer, tracker string, apiKey bool) error {
+	opts := checkOptions{out: w, config: true, apiKey: apiKey}
+	if tracker != "" {
+		opts.trackers = []string{tracker}
+	}
+	if !runChecks(opts) {
 		return fmt.Errorf("prerequisite checks failed; run `vcoding doctor` for details")
 	}
 	return nil
 }
 
-// runChecks performs prerequisite checks and prints results.
-// If verbose is true, prints both passed (✅) and failed (❌) checks.
-// If verbose is false, prints only failed checks.
-// If includeConfig is true, also checks config and API key.
+// checkOptions selects the checks made by runChecks.
+type checkOptions struct {
+	out     io.Writer // where results are printed; default stdout
+	verbose bool      // print passed checks too, and the project guidelines
+	config  bool      // check that the config loads and is valid
+	apiKey  bool      // check OPENROUTER_API_KEY; requires config
+	// trackers are the issue trackers whose access is checked: the gh CLI
+	// for "github", the credentials from the config (requires config) for
+	// "gitlab" and "jira".
+	trackers []string
+}
+
+func (o checkOptions) hasTracker(tracker string) bool {
+	return slices.Contains(o.trackers, tracker)
+}
+
+// configuredTrackers returns the issue trackers set up in the config: the
+// default tracker, and Jira when it has a base URL. Falls back to GitHub
+// when the config cannot be loaded.
+func configuredTrackers() []string {
+	cfg, err := config.Load()
+	if err != nil {
+		return []string{"github"}
+	}
+	trackers := []string{cfg.Tracker}
+	if cfg.Tracker == "" {
+		trackers[0] = "github"
+	}
+	if cfg.Tracker != "jira" && cfg.Jira.BaseURL != "" {
+		trackers = append(trackers, "jira")
+	}
+	return trackers
+}
+
+// runChecks performs the prerequisite checks selected by opts and prints
+// results. If opts.verbose is true, prints both passed (✅) and failed (❌)
+// checks; otherwise prints only failed checks.
 // Returns true if all checks passed.
-func runChecks(verbose, includeConfig bool) bool {
+func runChecks(opts checkOptions) bool {
 	allOK := true
+	out := opts.out
+	if out == nil {
+		out = os.Stdout
+	}
 
 	check := func(label string, ok bool, hint string) {
 		if ok {
-			if verbose {
-				fmt.Printf("✅ %s\n", label)
+			if opts.verbose {
+				fmt.Fprintf(out, "✅ %s\n", label)
 			}
 		} else {
-			fmt.Printf("❌ %s — %s\n", label, hint)
+			fmt.Fprintf(out, "❌ %s — %s\n", label, hint)
 			allOK = false
 		}
 	}
@@ -73,32 +121,75 @@ func runChecks(verbose, includeConfig bool) bool {
 	gitErr := exec.Command("git", "rev-parse", "--is-inside-work-tree").Run()
 	check("inside git repository", gitErr == nil, "run `git init` or cd to a git repo")
 
-	// 2. gh CLI
-	_, err = exec.LookPath("gh")
-	check("gh CLI installed", err == nil, "install gh: https://cli.github.com")
-	if err == nil {
-		ghVersionOK, ghVersionHint := checkGHVersion()
-		check("gh CLI version >= 2.0.0", ghVersionOK, ghVersionHint)
-		ghAuthErr := exec.Command("gh", "auth", "status").Run()
-		check("gh CLI authenticated", ghAuthErr == nil, "run `gh auth login` (or set GH_TOKEN in CI)")
+	// 2. gh CLI, for GitHub issues
+	if opts.hasTracker("github") {
+		_, err = exec.LookPath("gh")
+		check("gh CLI installed", err == nil, "install gh: https://cli.github.com")
+		if err == nil {
+			ghVersionOK, ghVersionHint := checkGHVersion()
+			check("gh CLI version >= 2.0.0", ghVersionOK, ghVersionHint)
+			ghAuthErr := exec.Command("gh", "auth", "status").Run()
+			check("gh CLI authenticated", ghAuthErr == nil, "run `gh auth login` (or set GH_TOKEN in CI)")
+		}
 	}
 
 	// 3. config (optional)
-	if includeConfig {
+	if opts.config {
 		cfg, cfgErr := config.Load()
 		check("config loadable", cfgErr == nil, fmt.Sprintf("fix config: %v", cfgErr))
 		if cfgErr == nil {
 			validateErr := cfg.Validate()
 			check("config valid", validateErr == nil, fmt.Sprintf("%v", validateErr))
 
-			apiKey := cfg.APIKey()
-			check("OPENROUTER_API_KEY set", apiKey != "", "set environment variable OPENROUTER_API_KEY")
+			if opts.apiKey {
+				apiKey := cfg.APIKey()
+				check("OPENROUTER_API_KEY set", apiKey != "", "set environment variable OPENROUTER_API_KEY")
+			}
+
+			if opts.hasTracker("gitlab") {
+				tokenEnv := cfg.GitLab.TokenEnv
+				if tokenEnv == "" {
+					tokenEnv = "GITLAB_TOKEN"
+				}
+				check(tokenEnv+" set", cfg.GitLabToken() != "", "set environment variable "+tokenEnv+" to a GitLab access token with read_api scope")
+			}
+			if opts.hasTracker("jira") {
+				check("jira.base_url set", cfg.Jira.BaseURL != "", "set jira.base_url in config, e.g. https://example.atlassian.net")
+				tokenEnv := cfg.Jira.TokenEnv
+				if tokenEnv == "" {
+					tokenEnv = "JIRA_API_TOKEN"
+				}
+				check(tokenEnv+" set", cfg.JiraToken() != "", "set environment variable "+tokenEnv+" to a Jira API token")
+				// Jira Cloud authenticates API tokens together with the
+				// account email; Server/Data Center (API v2) takes a
+				// personal access token alone.
+				if cfg.Jira.APIVersion != "2" {
+					emailEnv := cfg.Jira.EmailEnv
+					if emailEnv == "" {
+						emailEnv = "JIRA_EMAIL"
+					}
+					check(emailEnv+" set", cfg.JiraEmail() != "", "set environment variable "+emailEnv+" to the Jira account email (or set jira.api_version: \"2\" for a personal access token)")
+				}
+			}
+			if opts.verbose {
+				printGuidelines(&cfg.ProjectContext)
+			}
 		}
 	}
 
 	return allOK
 }
 
+// printGuidelines reports the project guidance files sent as
+// project:guidelines. Having none is not a failure.
+func printGuidelines(pc *config.ProjectCtxConfig) {
+	if files := project.GuidelineFiles(pc); len(files) > 0 {
+		fmt.Printf("✅ project guidelines: %s\n", strings.Join(files, ", "))
+		return
+	}
+	fmt.Println("ℹ️  no project guidelines — create .vcoding/GUIDELINES.md (or list AGENTS.md, CLAUDE.md, ... under project_context.guidelines) to send your conventions to the planner and reviewer")
+}
+
 // checkGHVersion returns true if gh version >= 2.0.0.
 func checkGHVersion() (bool, string) {
 	out, err := exec.Command("gh", "--version").Output()
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/futureCreator/vcoding/internal/config"
+	"github.com/futureCreator/vcoding/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var indexForce bool
+var indexQueryLimit int
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Manage the local code index",
+	Long: `Manage the on-disk code index under .vcoding/index/.
+
+Once built, the index is updated incrementally at the start of every run and
+used to rank project context files without re-reading the repository.`,
+}
+
+var indexBuildCmd = &cobra.Command{
+	Use:          "build",
+	Short:        "Build or update the code index",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runIndexBuild,
+}
+
+var indexStatusCmd = &cobra.Command{
+	Use:          "status",
+	Short:        "Show the code index and files changed since its last update",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runIndexStatus,
+}
+
+var indexQueryCmd = &cobra.Command{
+	Use:          "query <text>",
+	Short:        "Rank indexed files against a query",
+	Example:      `vcoding index query "retry on rate limit"`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runIndexQuery,
+}
+
+func init() {
+	rootCmd.AddCommand(indexCmd)
+	indexCmd.AddCommand(indexBuildCmd, indexStatusCmd, indexQueryCmd)
+	indexBuildCmd.Flags().BoolVar(&indexForce, "force", false, "Discard the existing index and rebuild it from scratch")
+	indexQueryCmd.Flags().IntVarP(&indexQueryLimit, "limit", "n", 10, "Maximum number of files to show")
+}
+
+func runIndexBuild(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	var idx *project.Index
+	if !indexForce {
+		idx, err = project.LoadIndex()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Rebuilding index: %v\n", err)
+		}
+	}
+	if idx == nil {
+		idx = project.NewIndex()
+	}
+
+	start := time.Now()
+	stats, err := idx.Update(&cfg.ProjectContext)
+	if err != nil {
+		return fmt.Errorf("updating index: %w", err)
+	}
+	if err := idx.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Indexed %d files, %d terms in %s (%d added, %d updated, %d removed)\n",
+		stats.Files, idx.TermCount(), time.Since(start).Round(time.Millisecond), stats.Added, stats.Updated, stats.Removed)
+	return nil
+}
+
+func runIndexStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	idx, err := project.LoadIndex()
+	if err != nil {
+		return err
+	}
+	if idx == nil {
+		fmt.Println("No index. Run 'vcoding index build' to create one.")
+		return nil
+	}
+
+	stats, err := idx.Stale(&cfg.ProjectContext)
+	if err != nil {
+		return fmt.Errorf("checking index: %w", err)
+	}
+	fmt.Printf("Index:   %s\n", project.IndexPath())
+	fmt.Printf("Files:   %d\n", stats.Files)
+	fmt.Printf("Terms:   %d\n", idx.TermCount())
+	fmt.Printf("Updated: %s\n", idx.Updated.Local().Format("2006-01-02 15:04:05"))
+	if stats.Changed() {
+		fmt.Printf("Stale:   %d new, %d changed, %d removed (updated automatically on the next run)\n", stats.Added, stats.Updated, stats.Removed)
+	} else {
+		fmt.Println("Stale:   none")
+	}
+	return nil
+}
+
+func runIndexQuery(cmd *cobra.Command, args []string) error {
+	idx, err := project.LoadIndex()
+	if err != nil {
+		return err
+	}
+	if idx == nil {
+		return fmt.Errorf("no index; run 'vcoding index build' first")
+	}
+
+	scores := idx.Rank(strings.Join(args, " "), "", nil)
+	shown := 0
+	for _, s := range scores {
+		if s.Score <= 0 || shown >= indexQueryLimit {
+			break
+		}
+		fmt.Printf("%7.2f  %s\n", s.Score, s.Path)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Println("No matching files.")
+	}
+	return nil
+}
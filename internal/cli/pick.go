@@ -1,25 +1,105 @@
 package cli
 
 import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/futureCreator/vcoding/internal/config"
+	"github.com/futureCreator/vcoding/internal/github"
+	"github.com/futureCreator/vcoding/internal/gitlab"
+	"github.com/futureCreator/vcoding/internal/jira"
+	"github.com/futureCreator/vcoding/internal/project"
 	"github.com/futureCreator/vcoding/internal/source"
 	"github.com/spf13/cobra"
 )
 
-var pickPipeline string
-var pickVerbose bool
+var pickOpts runOptions
 
 var pickCmd = &cobra.Command{
-	Use:          "pick <issue-number>",
-	Short:        "Run pipeline on a GitHub issue",
+	Use:   "pick <issue>",
+	Short: "Run pipeline on a GitHub, GitLab or Jira issue",
+	Long: `Run the pipeline on an issue of the configured tracker (tracker in config,
+GitHub by default). Prefix the issue number with the tracker to pick from
+another one, e.g. gitlab:123 or github:123. Jira issue keys such as PROJ-123
+always refer to Jira; GitHub issue URLs are passed to gh as they are.`,
+	Example:      "vcoding pick 42\nvcoding pick gitlab:123\nvcoding pick PROJ-123",
 	Args:         cobra.ExactArgs(1),
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		src := &source.GitHubSource{IssueNumber: args[0]}
-		return runPipeline(cmd.Context(), src, pickPipeline, pickVerbose)
+		cfg, err := config.LoadScope(pickOpts.Scope)
+		if err != nil {
+			err = fmt.Errorf("loading config: %w", err)
+			failEarly(pickOpts, pickOpts.Pipeline, err)
+			return err
+		}
+		src, err := issueSource(cfg, args[0])
+		if err != nil {
+			failEarly(pickOpts, pickOpts.Pipeline, err)
+			return err
+		}
+		return runPipeline(cmd.Context(), src, pickOpts)
 	},
 }
 
 func init() {
-	pickCmd.Flags().StringVarP(&pickPipeline, "pipeline", "p", "default", "Pipeline to use")
-	pickCmd.Flags().BoolVarP(&pickVerbose, "verbose", "v", false, "Stream executor output to terminal")
+	addRunFlags(pickCmd, &pickOpts)
+}
+
+// jiraKeyRe matches Jira issue keys such as "PROJ-123".
+var jiraKeyRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+
+// trackers are the issue trackers that can prefix an issue reference.
+var trackers = []string{"github", "gitlab", "jira"}
+
+// issueSource returns the source for an issue reference: a number on the
+// configured tracker, a Jira issue key, or "<tracker>:<id>". Any other
+// reference holding a colon, such as an issue URL, is passed on to gh.
+func issueSource(cfg *config.Config, ref string) (source.Source, error) {
+	tracker, id := cfg.Tracker, ref
+	if t, rest, ok := strings.Cut(ref, ":"); ok && slices.Contains(trackers, t) {
+		tracker, id = t, rest
+	} else if ok {
+		tracker = "github"
+	} else if jiraKeyRe.MatchString(ref) {
+		tracker = "jira"
+	}
+	if id == "" {
+		return nil, fmt.Errorf("invalid issue %q", ref)
+	}
+
+	switch tracker {
+	case "", "github":
+		filter := github.CommentFilter{
+			MinReactions:    cfg.GitHub.Comments.MinReactions,
+			MaintainersOnly: cfg.GitHub.Comments.MaintainersOnly,
+		}
+		return &source.GitHubSource{IssueNumber: id, Repo: project.RepoName(), Comments: filter}, nil
+	case "gitlab":
+		projectPath := cfg.GitLab.Project
+		if projectPath == "" {
+			projectPath = project.RepoName()
+		}
+		if projectPath == "" {
+			return nil, fmt.Errorf("gitlab.project is not set and could not be detected from the origin remote")
+		}
+		client := &gitlab.Client{BaseURL: cfg.GitLab.BaseURL, Token: cfg.GitLabToken()}
+		return &source.GitLabSource{Client: client, Project: projectPath, IID: id}, nil
+	case "jira":
+		if !jiraKeyRe.MatchString(id) {
+			return nil, fmt.Errorf("invalid Jira issue key %q (expected e.g. PROJ-123)", id)
+		}
+		if cfg.Jira.BaseURL == "" {
+			return nil, fmt.Errorf("jira.base_url is not set")
+		}
+		client := &jira.Client{
+			BaseURL:    cfg.Jira.BaseURL,
+			Email:      cfg.JiraEmail(),
+			Token:      cfg.JiraToken(),
+			APIVersion: cfg.Jira.APIVersion,
+		}
+		return &source.JiraSource{Client: client, Key: id, AcceptanceCriteriaField: cfg.Jira.AcceptanceCriteriaField}, nil
+	}
+	return nil, fmt.Errorf("unknown issue tracker %q (use github, gitlab or jira)", tracker)
 }
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/futureCreator/vcoding/internal/assets"
+	"github.com/futureCreator/vcoding/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+var promptsShowDefault bool
+var promptsEjectUser bool
+var promptsEjectForce bool
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Inspect and customize prompt templates",
+	Long: `Inspect and customize prompt templates.
+
+Prompts are resolved from three layers, highest priority first:
+  1. project  .vcoding/prompts/<name>.md
+  2. user     ~/.vcoding/prompts/<name>.md
+  3. embedded built-in defaults
+
+Partials shared between prompts live in a partials/ subdirectory of each layer.`,
+}
+
+var promptsListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List prompts and the layer each one is loaded from",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := assets.LoadPromptRegistry()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-20s %-8s %-9s %s\n", "Name", "Kind", "Layer", "Path")
+		for _, e := range reg.Entries() {
+			kind := "prompt"
+			if e.Partial {
+				kind = "partial"
+			}
+			fmt.Printf("%-20s %-8s %-9s %s\n", e.Name, kind, e.Layer, e.Path)
+		}
+		return nil
+	},
+}
+
+var promptsShowCmd = &cobra.Command{
+	Use:          "show <name>",
+	Short:        "Print the effective content of a prompt",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := assets.LoadPromptRegistry()
+		if err != nil {
+			return err
+		}
+		e, ok := reg.Lookup(args[0])
+		if !ok {
+			return fmt.Errorf("prompt %q not found", args[0])
+		}
+		content := e.Content
+		if promptsShowDefault {
+			if content, err = assets.EmbeddedPrompt(e.Name, e.Partial); err != nil {
+				return err
+			}
+		}
+		fmt.Print(content)
+		return nil
+	},
+}
+
+var promptsEjectCmd = &cobra.Command{
+	Use:          "eject <name>",
+	Short:        "Copy a built-in prompt into .vcoding/prompts/ for customization",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := assets.LoadPromptRegistry()
+		if err != nil {
+			return err
+		}
+		e, ok := reg.Lookup(args[0])
+		if !ok {
+			return fmt.Errorf("prompt %q not found", args[0])
+		}
+		content, err := assets.EmbeddedPrompt(e.Name, e.Partial)
+		if err != nil {
+			return err
+		}
+
+		dir := filepath.Join(".vcoding", "prompts")
+		if promptsEjectUser {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("getting home dir: %w", err)
+			}
+			dir = filepath.Join(home, ".vcoding", "prompts")
+		}
+		if e.Partial {
+			dir = filepath.Join(dir, "partials")
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating prompt dir: %w", err)
+		}
+
+		path := filepath.Join(dir, e.Name+".md")
+		if _, err := os.Stat(path); err == nil && !promptsEjectForce {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		fmt.Printf("Created %s\n", path)
+		return nil
+	},
+}
+
+var promptsDiffCmd = &cobra.Command{
+	Use:          "diff <name>",
+	Short:        "Show differences between the effective prompt and the built-in default",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := assets.LoadPromptRegistry()
+		if err != nil {
+			return err
+		}
+		e, ok := reg.Lookup(args[0])
+		if !ok {
+			return fmt.Errorf("prompt %q not found", args[0])
+		}
+		embedded, err := assets.EmbeddedPrompt(e.Name, e.Partial)
+		if err != nil {
+			return fmt.Errorf("prompt %q has no built-in default (defined in %s)", e.Name, e.Path)
+		}
+		out := diff.Unified("embedded/"+e.Name+".md", e.Path, embedded, e.Content, diff.DefaultContext)
+		if out == "" {
+			fmt.Printf("%s matches the built-in default.\n", e.Name)
+			return nil
+		}
+		fmt.Print(out)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promptsCmd)
+	promptsCmd.AddCommand(promptsListCmd, promptsShowCmd, promptsEjectCmd, promptsDiffCmd)
+	promptsShowCmd.Flags().BoolVar(&promptsShowDefault, "default", false, "Show the built-in default instead of the effective prompt")
+	promptsEjectCmd.Flags().BoolVar(&promptsEjectUser, "user", false, "Eject to ~/.vcoding/prompts/ instead of the project")
+	promptsEjectCmd.Flags().BoolVarP(&promptsEjectForce, "force", "f", false, "Overwrite an existing override")
+}
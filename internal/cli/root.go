@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/futureCreator/vcoding/pkg/version"
@@ -13,8 +14,9 @@ var rootCmd = &cobra.Command{
 	Long:  `vCoding orchestrates multiple AI models to take an issue or spec from input to PR automatically.`,
 }
 
-func Execute() error {
-	return rootCmd.Execute()
+// Execute runs the root command. Cancelling ctx interrupts a running pipeline.
+func Execute(ctx context.Context) error {
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
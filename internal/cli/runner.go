@@ -2,7 +2,9 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/futureCreator/vcoding/internal/assets"
@@ -11,24 +13,68 @@ import (
 	vlog "github.com/futureCreator/vcoding/internal/log"
 	"github.com/futureCreator/vcoding/internal/pipeline"
 	"github.com/futureCreator/vcoding/internal/project"
+	"github.com/futureCreator/vcoding/internal/prompt"
+	"github.com/futureCreator/vcoding/internal/redact"
 	"github.com/futureCreator/vcoding/internal/run"
 	"github.com/futureCreator/vcoding/internal/source"
+	"github.com/spf13/cobra"
 )
 
-// runPipeline is the shared entry point for pick and do commands.
-func runPipeline(ctx context.Context, src source.Source, pipelineName string, verbose bool) error {
-	if err := checkPrerequisites(); err != nil {
+// runOptions holds the flags shared by pick, do and ask.
+type runOptions struct {
+	Pipeline string
+	Verbose  bool
+	Output   string // "text" | "json"
+	DryRun   bool
+	Scope    string // subdirectory the run is limited to
+}
+
+// addRunFlags registers the shared run flags on cmd.
+func addRunFlags(cmd *cobra.Command, opts *runOptions) {
+	cmd.Flags().StringVarP(&opts.Pipeline, "pipeline", "p", "default", "Pipeline to use")
+	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false, "Stream executor output to terminal")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "text", "Output format: text or json (newline-delimited events)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Render each step's request into the run directory without calling the API")
+	cmd.Flags().StringVar(&opts.Scope, "scope", "", "Limit project context, git diff and repository map to a subdirectory")
+}
+
+// runPipeline is the shared entry point for pick, do and ask commands.
+func runPipeline(ctx context.Context, src source.Source, opts runOptions) (err error) {
+	if opts.Output != "text" && opts.Output != "json" {
+		return fmt.Errorf("invalid --output %q: must be text or json", opts.Output)
+	}
+	pipelineName := opts.Pipeline
+
+	// Failures before the display exists still end the JSON event stream.
+	var disp pipeline.Display
+	defer func() {
+		if err != nil && disp == nil {
+			failEarly(opts, pipelineName, err)
+		}
+	}()
+
+	// Check results must not mix with JSON events on stdout.
+	checkOut := io.Writer(os.Stdout)
+	if opts.Output == "json" {
+		checkOut = os.Stderr
+	}
+	// A dry run never calls the API, so it does not need an API key.
+	if err := checkPrerequisites(checkOut, sourceTracker(src), !opts.DryRun); err != nil {
 		return err
 	}
 
 	// Load config
-	cfg, err := config.Load()
+	cfg, err := config.LoadScope(opts.Scope)
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
+	redactor, err := redact.New(cfg.Redaction)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
 
 	// Init logging
 	logFile := openLogFile()
@@ -56,59 +102,319 @@ func runPipeline(ctx context.Context, src source.Source, pipelineName string, ve
 		return fmt.Errorf("loading pipeline %q: %w", pipelineName, err)
 	}
 
+	// Load and parse prompt templates
+	prompts, err := loadPrompts()
+	if err != nil {
+		return fmt.Errorf("loading prompts: %w", err)
+	}
+
+	// Redact the ticket, which also reaches prompts as template data and the
+	// display. A secret in abort mode fails the run once it exists.
+	title, titleErr := redactor.Redact("ticket:title", "", input.Title)
+
 	// Create run directory
 	r, err := run.New(input.Mode, input.Ref, input.Slug, gitInfo.Branch, gitInfo.Commit)
 	if err != nil {
 		return fmt.Errorf("creating run: %w", err)
 	}
+	defer saveRedactions(r, redactor)
 
-	// Write TICKET.md to run directory
-	ticketContent := pipeline.BuildTicketContent(input.Title, input.Body)
-	if err := r.WriteFile("TICKET.md", ticketContent); err != nil {
-		return fmt.Errorf("writing ticket: %w", err)
+	// Start the display right away so that every failure from here on is
+	// reported to it.
+	if opts.Output == "json" {
+		disp = pipeline.NewJSONDisplay(title, r.ID, r.Dir, ppl.Name)
+	} else {
+		disp = pipeline.NewDisplay(title, opts.Verbose)
 	}
+	disp.Header()
 
-	// Load all prompt templates
-	prompts, err := assets.AllPrompts()
-	if err != nil {
-		return fmt.Errorf("loading prompts: %w", err)
+	if input.Tracker != "" {
+		r.Meta.Ticket = ticketMeta(input)
+	}
+	if opts.DryRun || cfg.ProjectContext.Scope != "" || r.Meta.Ticket != nil {
+		r.Meta.DryRun = opts.DryRun
+		r.Meta.Scope = cfg.ProjectContext.Scope
+		if err := r.SaveMeta(); err != nil {
+			return abortRun(r, disp, fmt.Errorf("saving run meta: %w", err))
+		}
+	}
+
+	if titleErr != nil {
+		return abortRun(r, disp, titleErr)
+	}
+	input.Title = title
+	if input.Body, err = redactor.Redact("ticket:body", "", input.Body); err != nil {
+		return abortRun(r, disp, err)
+	}
+
+	// Write TICKET.md to run directory
+	ticketContent := pipeline.BuildTicketContent(input.Title, input.Body)
+	if err := r.WriteArtifact("TICKET.md", "", ticketContent); err != nil {
+		return abortRun(r, disp, fmt.Errorf("writing ticket: %w", err))
 	}
 
 	// Build executors
-	executors := buildExecutors(cfg, prompts)
+	executors := buildExecutors(cfg)
 
 	// Collect project context
-	projectCtxStr := ""
-	if files, err := project.Scan(&cfg.ProjectContext); err == nil {
-		projectCtxStr = project.FormatContext(files)
-	} else {
-		vlog.Warn("could not scan project files", "err", err)
+	projectCtxStr, err := buildProjectContext(cfg, input, r, redactor)
+	if err != nil {
+		return abortRun(r, disp, err)
 	}
 
 	// Collect git diff
-	gitDiff, _ := project.Diff()
+	gitDiff, _ := project.Diff(cfg.ProjectContext.Scope)
+	gitDiff, err = redactor.Redact("git:diff", "", gitDiff)
+	if err != nil {
+		return abortRun(r, disp, err)
+	}
+	if n := len(redactor.Findings()); n > 0 {
+		vlog.Warn("redacted possible secrets before sending them to the provider", "count", n, "report", redactionsFile)
+	}
 
 	// Build pipeline context
 	pipelineCtx := &pipeline.Context{
 		RunDir:     r.Dir,
 		ProjectCtx: projectCtxStr,
 		GitDiff:    gitDiff,
+		Config:     cfg,
+		Redactor:   redactor,
+	}
+	if input.Tracker == "github" {
+		pipelineCtx.IssueRef = input.Ref
 	}
 
 	// Run pipeline
-	disp := pipeline.NewDisplay(input.Title, verbose)
-	disp.Header()
-
 	engine := &pipeline.Engine{
-		Config:    cfg,
-		Pipeline:  ppl,
-		Executors: executors,
-		Run:       r,
-		Display:   disp,
-		Verbose:   verbose,
+		Config:     cfg,
+		Pipeline:   ppl,
+		Executors:  executors,
+		Prompts:    prompts,
+		PromptData: buildPromptData(cfg, input, r, gitInfo),
+		Run:        r,
+		Display:    disp,
+		Verbose:    opts.Verbose,
+		DryRun:     opts.DryRun,
 	}
 
-	return engine.Execute(ctx, pipelineCtx)
+	if err := engine.Validate(); err != nil {
+		if failErr := r.Fail(err.Error()); failErr != nil {
+			vlog.Error("failed to update run meta", "err", failErr)
+		}
+		disp.Failed(r.Meta.TotalCost, err)
+		return fmt.Errorf("invalid pipeline %q: %w", ppl.Name, err)
+	}
+
+	if err := engine.Execute(ctx, pipelineCtx); err != nil {
+		return err
+	}
+	if opts.DryRun && opts.Output == "text" {
+		fmt.Printf("Dry run: requests written to %s (<Step>.system.md, <Step>.user.md).\n", r.Dir)
+		fmt.Println("Costs are input-only estimates from built-in model pricing; — means the model has no known pricing.")
+	}
+	return nil
+}
+
+// sourceTracker returns the issue tracker src fetches from, "" for spec
+// files and messages.
+func sourceTracker(src source.Source) string {
+	switch src.(type) {
+	case *source.GitHubSource:
+		return "github"
+	case *source.GitLabSource:
+		return "gitlab"
+	case *source.JiraSource:
+		return "jira"
+	}
+	return ""
+}
+
+// maxRankingEntries bounds the number of scores written to context-ranking.json.
+const maxRankingEntries = 200
+
+// buildProjectContext scans the project and selects the files most relevant
+// to the ticket within the project_context budgets, outlining Go files in
+// outline mode. When a code index exists, it is updated and used for ranking
+// instead of reading every file. The ranking is saved to context-ranking.json
+// in the run directory. Secrets in the selected files are redacted; the only
+// error returned is a *redact.SecretsError in abort mode.
+func buildProjectContext(cfg *config.Config, input *source.Input, r *run.Run, redactor *redact.Redactor) (string, error) {
+	pc := &cfg.ProjectContext
+	query := input.Title + "\n" + input.Body
+
+	var scores []project.FileScore
+	var selected []project.FileEntry
+	if idx := updatedIndex(pc); idx != nil {
+		scores = idx.Rank(query, pc.Scope, recentChanges())
+		selected = idx.Select(pc, scores)
+		vlog.Debug("project context from index", "scope", pc.Scope, "candidates", len(scores), "selected", len(selected))
+	} else {
+		files, err := project.Collect(pc)
+		if err != nil {
+			vlog.Warn("could not scan project files", "err", err)
+			return "", nil
+		}
+		if pc.Ranking != "none" {
+			scores = project.Rank(files, query, recentChanges())
+		}
+		if pc.Mode == "outline" {
+			files = project.Outlines(files, scores, pc.FullFiles)
+		}
+		selected = project.Select(files, scores, pc.MaxFiles, pc.MaxTokens)
+		vlog.Debug("project context", "scope", pc.Scope, "candidates", len(files), "selected", len(selected))
+	}
+
+	if scores != nil {
+		if len(scores) > maxRankingEntries {
+			scores = scores[:maxRankingEntries]
+		}
+		if data, err := json.MarshalIndent(scores, "", "  "); err == nil {
+			if err := r.WriteFile("context-ranking.json", string(data)+"\n"); err != nil {
+				vlog.Warn("failed to save context ranking", "err", err)
+			}
+		}
+	}
+	for i, f := range selected {
+		content, err := redactor.Redact("project:context", f.Path, f.Content)
+		if err != nil {
+			return "", err
+		}
+		selected[i].Content = content
+	}
+	return project.FormatContext(selected), nil
+}
+
+// redactionsFile is the run artifact listing redacted secrets.
+const redactionsFile = "redactions.json"
+
+// saveRedactions writes the redaction report to the run directory when any
+// secret was found. The report holds placeholders, never the secrets.
+func saveRedactions(r *run.Run, redactor *redact.Redactor) {
+	findings := redactor.Findings()
+	if len(findings) == 0 {
+		return
+	}
+	data, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := r.WriteFile(redactionsFile, string(data)+"\n"); err != nil {
+		vlog.Warn("failed to save redaction report", "err", err)
+	}
+}
+
+// abortRun marks the run failed before the pipeline started and reports it
+// to the display.
+func abortRun(r *run.Run, disp pipeline.Display, err error) error {
+	if failErr := r.Fail(err.Error()); failErr != nil {
+		vlog.Error("failed to update run meta", "err", failErr)
+	}
+	disp.Failed(r.Meta.TotalCost, err)
+	return err
+}
+
+// failEarly reports err, which ended a run before it had a run directory or
+// display, as a failed run_completed event in JSON output. Text output
+// leaves reporting err to the caller.
+func failEarly(opts runOptions, pipelineName string, err error) {
+	if opts.Output == "json" {
+		pipeline.NewJSONDisplay("", "", "", pipelineName).Failed(0, err)
+	}
+}
+
+// updatedIndex loads the code index, if one has been built and relevance
+// ranking is enabled, and brings it up to date with the working tree.
+// Returns nil when the project should be scanned instead.
+func updatedIndex(pc *config.ProjectCtxConfig) *project.Index {
+	if pc.Ranking == "none" {
+		return nil
+	}
+	idx, err := project.LoadIndex()
+	if err != nil {
+		vlog.Warn("ignoring code index", "err", err)
+		return nil
+	}
+	if idx == nil {
+		return nil
+	}
+	stats, err := idx.Update(pc)
+	if err != nil {
+		vlog.Warn("could not update code index", "err", err)
+		return nil
+	}
+	if stats.Changed() {
+		if err := idx.Save(); err != nil {
+			vlog.Warn("failed to save code index", "err", err)
+		}
+	}
+	vlog.Debug("code index updated", "files", stats.Files, "added", stats.Added, "updated", stats.Updated, "removed", stats.Removed)
+	return idx
+}
+
+// recentChanges returns the files changed recently for relevance ranking.
+func recentChanges() map[string]int {
+	recent, err := project.RecentChanges(project.RecentCommits)
+	if err != nil {
+		vlog.Debug("could not collect recent changes", "err", err)
+	}
+	return recent
+}
+
+// loadPrompts parses the layered prompt templates together with the shared partials.
+func loadPrompts() (*prompt.Set, error) {
+	reg, err := assets.LoadPromptRegistry()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range reg.Entries() {
+		vlog.Debug("prompt resolved", "name", e.Name, "layer", e.Layer, "path", e.Path)
+	}
+	return prompt.New(reg.Templates(), reg.PartialTemplates())
+}
+
+// ticketMeta records the tracker metadata of a picked issue for meta.json.
+func ticketMeta(input *source.Input) *run.TicketMeta {
+	t := &run.TicketMeta{Tracker: input.Tracker, Labels: input.Labels}
+	for _, f := range input.Fields {
+		if f.Value == "" {
+			continue
+		}
+		if t.Fields == nil {
+			t.Fields = map[string]string{}
+		}
+		t.Fields[f.Name] = f.Value
+	}
+	for _, c := range input.Comments {
+		t.Comments = append(t.Comments, run.TicketComment{Author: c.Author, CreatedAt: c.CreatedAt, Role: c.Role, Reactions: c.Reactions})
+	}
+	for _, r := range input.References {
+		t.References = append(t.References, run.TicketReference{Ref: r.Ref, Title: r.Title, State: r.State, PullRequest: r.PullRequest})
+	}
+	return t
+}
+
+// buildPromptData assembles the run-wide data passed to prompt templates.
+func buildPromptData(cfg *config.Config, input *source.Input, r *run.Run, gitInfo *project.GitInfo) prompt.Data {
+	repo := cfg.GitHub.DefaultRepo
+	if repo == "" {
+		repo = project.RepoName()
+	}
+	return prompt.Data{
+		Ticket: prompt.TicketData{
+			Title:  input.Title,
+			Body:   input.Body,
+			Ref:    input.Ref,
+			Mode:   input.Mode,
+			Labels: input.Labels,
+		},
+		Run:    prompt.RunData{ID: r.ID, Dir: r.Dir},
+		Config: cfg,
+		Git: prompt.GitData{
+			Repo:   repo,
+			Branch: gitInfo.Branch,
+			Commit: gitInfo.Commit,
+		},
+	}
 }
 
 func loadPipeline(cfg *config.Config, name string) (*pipeline.Pipeline, error) {
@@ -125,9 +431,9 @@ func loadPipeline(cfg *config.Config, name string) (*pipeline.Pipeline, error) {
 	return pipeline.Parse(data)
 }
 
-func buildExecutors(cfg *config.Config, prompts map[string]string) map[string]executor.Executor {
+func buildExecutors(cfg *config.Config) map[string]executor.Executor {
 	return map[string]executor.Executor{
-		"api": &executor.APIExecutor{Config: cfg, Prompts: prompts},
+		"api": &executor.APIExecutor{Config: cfg},
 	}
 }
 
@@ -47,6 +47,10 @@ func runStats(cmd *cobra.Command, args []string) error {
 		if err := json.Unmarshal(data, &meta); err != nil {
 			continue
 		}
+		// Dry runs only carry cost estimates.
+		if meta.DryRun {
+			continue
+		}
 		stats = append(stats, runStat{id: e.Name(), meta: meta})
 	}
 
@@ -61,7 +65,7 @@ func runStats(cmd *cobra.Command, args []string) error {
 	})
 
 	var totalCost float64
-	var completed, failed int
+	var completed, failed, cancelled int
 	for _, s := range stats {
 		totalCost += s.meta.TotalCost
 		switch s.meta.Status {
@@ -69,10 +73,12 @@ func runStats(cmd *cobra.Command, args []string) error {
 			completed++
 		case "failed":
 			failed++
+		case "cancelled":
+			cancelled++
 		}
 	}
 
-	fmt.Printf("Runs: %d total, %d completed, %d failed\n", len(stats), completed, failed)
+	fmt.Printf("Runs: %d total, %d completed, %d failed, %d cancelled\n", len(stats), completed, failed, cancelled)
 	fmt.Printf("Total cost: $%.4f\n", totalCost)
 	if len(stats) > 0 {
 		fmt.Printf("Average cost: $%.4f\n", totalCost/float64(len(stats)))
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
+	"github.com/futureCreator/vcoding/internal/types"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,8 +16,13 @@ type Config struct {
 	Provider         ProviderConfig   `yaml:"provider"`
 	Roles            RolesConfig      `yaml:"roles"`
 	GitHub           GitHubConfig     `yaml:"github"`
+	GitLab           GitLabConfig     `yaml:"gitlab"`
+	Jira             JiraConfig       `yaml:"jira"`
+	Tracker          string           `yaml:"tracker"` // issue tracker used by pick: "github" (default), "gitlab" or "jira"
 	Language         LanguageConfig   `yaml:"language"`
 	ProjectContext   ProjectCtxConfig `yaml:"project_context"`
+	Redaction        RedactionConfig  `yaml:"redaction"`
+	Hooks            types.Hooks      `yaml:"hooks"`
 	MaxContextTokens int              `yaml:"max_context_tokens"`
 	LogLevel         string           `yaml:"log_level"`
 }
@@ -32,8 +40,59 @@ type RolesConfig struct {
 }
 
 type GitHubConfig struct {
-	DefaultRepo string `yaml:"default_repo"`
-	BaseBranch  string `yaml:"base_branch"`
+	DefaultRepo string               `yaml:"default_repo"`
+	BaseBranch  string               `yaml:"base_branch"`
+	Comments    GitHubCommentsConfig `yaml:"comments"`
+}
+
+// GitHubCommentsConfig selects the issue comments added to TICKET.md by
+// pick. With no criterion set, all comments are added; otherwise comments
+// meeting any criterion, and those by the issue author, are added.
+type GitHubCommentsConfig struct {
+	MinReactions    int  `yaml:"min_reactions"`    // comments with at least this many reactions
+	MaintainersOnly bool `yaml:"maintainers_only"` // comments by owners, members and collaborators
+}
+
+// GitLabConfig configures the GitLab issue source.
+type GitLabConfig struct {
+	BaseURL  string `yaml:"base_url"`  // GitLab instance, e.g. https://gitlab.example.com
+	TokenEnv string `yaml:"token_env"` // environment variable holding the access token
+	Project  string `yaml:"project"`   // project path such as "group/repo"; empty = from the origin remote
+}
+
+// GitLabToken returns the resolved GitLab access token.
+func (c *Config) GitLabToken() string {
+	if c.GitLab.TokenEnv == "" {
+		return os.Getenv("GITLAB_TOKEN")
+	}
+	return os.Getenv(c.GitLab.TokenEnv)
+}
+
+// JiraConfig configures the Jira issue source.
+type JiraConfig struct {
+	BaseURL    string `yaml:"base_url"`    // Jira site, e.g. https://example.atlassian.net
+	EmailEnv   string `yaml:"email_env"`   // environment variable holding the account email (Jira Cloud)
+	TokenEnv   string `yaml:"token_env"`   // environment variable holding the API or personal access token
+	APIVersion string `yaml:"api_version"` // REST API version: "3" (Jira Cloud) or "2" (Jira Server/Data Center)
+	// AcceptanceCriteriaField is the custom field holding acceptance
+	// criteria, e.g. "customfield_10100"; empty if there is none.
+	AcceptanceCriteriaField string `yaml:"acceptance_criteria_field"`
+}
+
+// JiraEmail returns the resolved Jira account email.
+func (c *Config) JiraEmail() string {
+	if c.Jira.EmailEnv == "" {
+		return os.Getenv("JIRA_EMAIL")
+	}
+	return os.Getenv(c.Jira.EmailEnv)
+}
+
+// JiraToken returns the resolved Jira API token.
+func (c *Config) JiraToken() string {
+	if c.Jira.TokenEnv == "" {
+		return os.Getenv("JIRA_API_TOKEN")
+	}
+	return os.Getenv(c.Jira.TokenEnv)
 }
 
 type LanguageConfig struct {
@@ -42,10 +101,25 @@ type LanguageConfig struct {
 }
 
 type ProjectCtxConfig struct {
-	MaxFiles        int      `yaml:"max_files"`
-	MaxFileSize     string   `yaml:"max_file_size"`
-	IncludePatterns []string `yaml:"include_patterns"`
-	ExcludePatterns []string `yaml:"exclude_patterns"`
+	MaxFiles         int      `yaml:"max_files"`
+	MaxFileSize      string   `yaml:"max_file_size"`
+	IncludePatterns  []string `yaml:"include_patterns"`
+	ExcludePatterns  []string `yaml:"exclude_patterns"`
+	RespectGitignore bool     `yaml:"respect_gitignore"` // honor .gitignore and .git/info/exclude
+	TrackedOnly      bool     `yaml:"tracked_only"`      // scan only files tracked by git
+	MaxTokens        int      `yaml:"max_tokens"`        // estimated token budget for all files, 0 = unlimited
+	Ranking          string   `yaml:"ranking"`           // "relevance" (default) or "none" for walk order
+	Mode             string   `yaml:"mode"`              // "full" (default) or "outline" for Go outlines
+	FullFiles        int      `yaml:"full_files"`        // outline mode: most relevant files kept in full
+	MapTokens        int      `yaml:"map_tokens"`        // estimated token budget for project:map, 0 = unlimited
+	Scope            string   `yaml:"scope"`             // subdirectory scanning, git diffs and project:map are limited to
+	Guidelines       []string `yaml:"guidelines"`        // project guidance files for project:guidelines, in order
+}
+
+// RedactionConfig controls secret redaction in content sent to providers.
+type RedactionConfig struct {
+	Mode     string   `yaml:"mode"`     // "redact" (default), "abort" or "off"
+	Patterns []string `yaml:"patterns"` // extra regexes; the first capturing group, or the whole match, is redacted
 }
 
 // Validate checks that required fields are present.
@@ -53,7 +127,43 @@ func (c *Config) Validate() error {
 	if c.Provider.Endpoint == "" {
 		return fmt.Errorf("provider.endpoint is required")
 	}
-	return nil
+	switch c.Tracker {
+	case "", "github", "gitlab", "jira":
+	default:
+		return fmt.Errorf("tracker must be \"github\", \"gitlab\" or \"jira\", got %q", c.Tracker)
+	}
+	if c.GitHub.Comments.MinReactions < 0 {
+		return fmt.Errorf("github.comments.min_reactions must not be negative")
+	}
+	switch c.Jira.APIVersion {
+	case "", "2", "3":
+	default:
+		return fmt.Errorf("jira.api_version must be \"2\" or \"3\", got %q", c.Jira.APIVersion)
+	}
+	if u := c.Jira.BaseURL; u != "" && !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		return fmt.Errorf("jira.base_url must be an http(s) URL, got %q", u)
+	}
+	switch c.ProjectContext.Ranking {
+	case "", "relevance", "none":
+	default:
+		return fmt.Errorf("project_context.ranking must be \"relevance\" or \"none\", got %q", c.ProjectContext.Ranking)
+	}
+	switch c.ProjectContext.Mode {
+	case "", "full", "outline":
+	default:
+		return fmt.Errorf("project_context.mode must be \"full\" or \"outline\", got %q", c.ProjectContext.Mode)
+	}
+	switch c.Redaction.Mode {
+	case "", "redact", "abort", "off":
+	default:
+		return fmt.Errorf("redaction.mode must be \"redact\", \"abort\" or \"off\", got %q", c.Redaction.Mode)
+	}
+	for i, p := range c.Redaction.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("redaction.patterns[%d]: %w", i, err)
+		}
+	}
+	return c.Hooks.Validate()
 }
 
 // APIKey returns the resolved OpenRouter API key.
@@ -86,6 +196,64 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// LoadScope is Load for a run limited to scope, a subdirectory of the
+// project; an empty scope means project_context.scope. The scope's own
+// .vcoding/config.yaml, if present, is layered on top of the project config.
+func LoadScope(scope string) (*Config, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if scope == "" {
+		scope = cfg.ProjectContext.Scope
+	}
+	scope, err = CleanScope(scope)
+	if err != nil {
+		return nil, err
+	}
+	if scope == "" {
+		return cfg, nil
+	}
+	dir := filepath.FromSlash(scope)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("scope %s is not a directory", scope)
+	}
+	scopePath := filepath.Join(dir, ".vcoding", "config.yaml")
+	if err := mergeFile(cfg, scopePath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("loading scope config: %w", err)
+	}
+	cfg.ProjectContext.Scope = scope
+	return cfg, nil
+}
+
+// CleanScope normalizes a scope to a clean slash-separated path relative to
+// the project root, "" for the whole project. Scopes outside the project are
+// rejected.
+func CleanScope(scope string) (string, error) {
+	if scope == "" {
+		return "", nil
+	}
+	if filepath.IsAbs(scope) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		rel, err := filepath.Rel(wd, scope)
+		if err != nil {
+			return "", fmt.Errorf("scope %s: %w", scope, err)
+		}
+		scope = rel
+	}
+	clean := filepath.ToSlash(filepath.Clean(scope))
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("scope %s is outside the project", scope)
+	}
+	if clean == "." {
+		return "", nil
+	}
+	return clean, nil
+}
+
 func mergeFile(dst *Config, path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -132,15 +300,35 @@ func defaults() *Config {
 		GitHub: GitHubConfig{
 			BaseBranch: "main",
 		},
+		GitLab: GitLabConfig{
+			BaseURL:  "https://gitlab.com",
+			TokenEnv: "GITLAB_TOKEN",
+		},
+		Jira: JiraConfig{
+			EmailEnv:   "JIRA_EMAIL",
+			TokenEnv:   "JIRA_API_TOKEN",
+			APIVersion: "3",
+		},
+		Tracker: "github",
 		Language: LanguageConfig{
 			Artifacts:       "en",
 			NormalizeTicket: true,
 		},
 		ProjectContext: ProjectCtxConfig{
-			MaxFiles:        20,
-			MaxFileSize:     "50KB",
-			IncludePatterns: []string{"*.go", "*.rs", "*.ts", "*.py", "*.md"},
-			ExcludePatterns: []string{"vendor/", "node_modules/", ".git/", ".vcoding/"},
+			MaxFiles:         20,
+			MaxFileSize:      "50KB",
+			IncludePatterns:  []string{"*.go", "*.rs", "*.ts", "*.py", "*.md"},
+			ExcludePatterns:  []string{"vendor/", "node_modules/", ".git/", ".vcoding/"},
+			RespectGitignore: true,
+			MaxTokens:        60000,
+			Ranking:          "relevance",
+			Mode:             "full",
+			FullFiles:        5,
+			MapTokens:        4000,
+			Guidelines:       []string{".vcoding/GUIDELINES.md"},
+		},
+		Redaction: RedactionConfig{
+			Mode: "redact",
 		},
 		MaxContextTokens: 80000,
 		LogLevel:         "info",
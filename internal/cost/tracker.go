@@ -46,6 +46,16 @@ func FromUsage(model string, usage Usage) float64 {
 		float64(usage.CompletionTokens)*pricing.OutputPerToken
 }
 
+// EstimateInput returns the cost of sending promptTokens to model, based on
+// the built-in pricing. Returns 0, false if the model has no known pricing.
+func EstimateInput(model string, promptTokens int) (float64, bool) {
+	pricing, ok := defaultPricing[model]
+	if !ok {
+		return 0, false
+	}
+	return float64(promptTokens) * pricing.InputPerToken, true
+}
+
 func parseFloat(s string, v *float64) (int, error) {
 	_, err := fmt.Sscanf(s, "%f", v)
 	return 1, err
@@ -0,0 +1,49 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", 3, ""},
+		{
+			name: "change", a: "a\nb\nc\n", b: "a\nB\nc\n", context: 3,
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "insert into empty", a: "", b: "x\ny\n", context: 3,
+			want: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "delete all", a: "x\ny\n", b: "", context: 3,
+			want: "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-x\n-y\n",
+		},
+		{
+			name: "missing final newline", a: "a\nb", b: "a\nb\nc", context: 1,
+			want: "--- old\n+++ new\n@@ -2,1 +2,2 @@\n b\n+c\n",
+		},
+		{
+			name: "separate hunks", a: "1\n2\n3\n4\n5\n6\n7\n8\n9\n", b: "1\nX\n3\n4\n5\n6\n7\nY\n9\n", context: 1,
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n 1\n-2\n+X\n 3\n@@ -7,3 +7,3 @@\n 7\n-8\n+Y\n 9\n",
+		},
+		{
+			name: "close changes share a hunk", a: "1\n2\n3\n4\n5\n", b: "1\nX\n3\nY\n5\n", context: 1,
+			want: "--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n-4\n+Y\n 5\n",
+		},
+		{
+			name: "no context", a: "a\nb\nc\n", b: "a\nc\n", context: 0,
+			want: "--- old\n+++ new\n@@ -2,1 +1,0 @@\n-b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.a, tt.b, tt.context); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
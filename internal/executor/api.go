@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -13,19 +14,26 @@ import (
 
 	"github.com/futureCreator/vcoding/internal/config"
 	"github.com/futureCreator/vcoding/internal/cost"
+	"github.com/futureCreator/vcoding/internal/input"
 	vlog "github.com/futureCreator/vcoding/internal/log"
 )
 
 // APIExecutor calls the OpenRouter API (OpenAI-compatible).
 type APIExecutor struct {
 	Config     *config.Config
-	Prompts    map[string]string // template name → content
 	HTTPClient *http.Client
 }
 
 type chatRequest struct {
-	Model    string        `json:"model"`
-	Messages []chatMessage `json:"messages"`
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+	Seed          *int           `json:"seed,omitempty"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type chatMessage struct {
@@ -33,25 +41,37 @@ type chatMessage struct {
 	Content string `json:"content"`
 }
 
+type chatUsage struct {
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	Cost             *float64 `json:"cost,omitempty"` // reported by OpenRouter
+}
+
 type chatResponse struct {
 	Choices []struct {
 		Message chatMessage `json:"message"`
 	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-	} `json:"usage"`
+	Usage chatUsage `json:"usage"`
 }
 
+// chatChunk is a single server-sent event of a streamed completion.
+type chatChunk struct {
+	Choices []struct {
+		Delta chatMessage `json:"delta"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Execute sends the step to the chat completions endpoint. When req.OnDelta is
+// set the completion is streamed and each content delta is forwarded to it.
 func (e *APIExecutor) Execute(ctx context.Context, req *Request) (*Result, error) {
 	start := time.Now()
 
-	systemPrompt, err := e.resolvePrompt(req.Step.PromptTemplate)
-	if err != nil {
-		return nil, err
-	}
-
-	userContent := buildUserContent(req)
+	systemPrompt := req.SystemPrompt
+	userContent := UserContent(req)
 
 	model := req.Step.Model
 	if model == "" {
@@ -64,6 +84,17 @@ func (e *APIExecutor) Execute(ctx context.Context, req *Request) (*Result, error
 			{Role: "system", Content: systemPrompt},
 			{Role: "user", Content: userContent},
 		},
+		Seed: req.Seed,
+	}
+	if req.Correction != nil {
+		payload.Messages = append(payload.Messages,
+			chatMessage{Role: "assistant", Content: req.Correction.PreviousOutput},
+			chatMessage{Role: "user", Content: req.Correction.Feedback},
+		)
+	}
+	if req.OnDelta != nil {
+		payload.Stream = true
+		payload.StreamOptions = &streamOptions{IncludeUsage: true}
 	}
 
 	body, err := json.Marshal(payload)
@@ -96,32 +127,32 @@ func (e *APIExecutor) Execute(ctx context.Context, req *Request) (*Result, error
 	}
 	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
 	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("API returned %d: %s", resp.StatusCode, string(respBody))
 	}
 
-	var chatResp chatResponse
-	if err := json.Unmarshal(respBody, &chatResp); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+	var output string
+	var usage chatUsage
+	if payload.Stream {
+		output, usage, err = readStream(resp.Body, req.OnDelta)
+	} else {
+		output, usage, err = readResponse(resp.Body)
 	}
-	if len(chatResp.Choices) == 0 {
-		return nil, fmt.Errorf("empty choices in API response")
+	if err != nil {
+		return nil, err
 	}
 
-	output := chatResp.Choices[0].Message.Content
-
-	// Cost extraction: header > usage > 0+warn
+	// Cost extraction: header > reported usage cost > usage pricing > 0+warn
 	var apiCost float64
 	if c, ok := cost.FromHeader(resp.Header.Get("x-openrouter-cost")); ok {
 		apiCost = c
-	} else if chatResp.Usage.PromptTokens > 0 {
+	} else if usage.Cost != nil {
+		apiCost = *usage.Cost
+	} else if usage.PromptTokens > 0 {
 		apiCost = cost.FromUsage(model, cost.Usage{
-			PromptTokens:     chatResp.Usage.PromptTokens,
-			CompletionTokens: chatResp.Usage.CompletionTokens,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
 		})
 	} else {
 		vlog.Warn("could not determine cost for step", "model", model)
@@ -131,33 +162,73 @@ func (e *APIExecutor) Execute(ctx context.Context, req *Request) (*Result, error
 		Output:    output,
 		Cost:      apiCost,
 		Duration:  time.Since(start),
-		TokensIn:  chatResp.Usage.PromptTokens,
-		TokensOut: chatResp.Usage.CompletionTokens,
+		TokensIn:  usage.PromptTokens,
+		TokensOut: usage.CompletionTokens,
 	}, nil
 }
 
-func (e *APIExecutor) resolvePrompt(template string) (string, error) {
-	if template == "" {
-		return "", nil
+func readResponse(r io.Reader) (string, chatUsage, error) {
+	respBody, err := io.ReadAll(r)
+	if err != nil {
+		return "", chatUsage{}, fmt.Errorf("reading response: %w", err)
 	}
-	if content, ok := e.Prompts[template]; ok {
-		return content, nil
+	var chatResp chatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return "", chatUsage{}, fmt.Errorf("parsing response: %w", err)
 	}
-	return "", fmt.Errorf("prompt template %q not found", template)
-}
-
-// ResolvePrompt exposes prompt lookup for external callers (e.g. token budget accounting).
-func (e *APIExecutor) ResolvePrompt(name string) (string, bool) {
-	content, ok := e.Prompts[name]
-	return content, ok
+	if len(chatResp.Choices) == 0 {
+		return "", chatUsage{}, fmt.Errorf("empty choices in API response")
+	}
+	return chatResp.Choices[0].Message.Content, chatResp.Usage, nil
 }
 
-// diffKeys are virtual input keys that should be rendered as diff code blocks.
-var diffKeys = map[string]string{
-	"git:diff": "git diff",
+// readStream consumes a server-sent event stream of completion chunks,
+// forwarding content deltas to onDelta.
+func readStream(r io.Reader, onDelta func(string)) (string, chatUsage, error) {
+	var out strings.Builder
+	var usage chatUsage
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Blank lines separate events; lines starting with ":" are keep-alive comments.
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return out.String(), usage, fmt.Errorf("parsing stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return out.String(), usage, fmt.Errorf("API stream error: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content == "" {
+				continue
+			}
+			out.WriteString(c.Delta.Content)
+			onDelta(c.Delta.Content)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return out.String(), usage, fmt.Errorf("reading stream: %w", err)
+	}
+	if out.Len() == 0 {
+		return "", usage, fmt.Errorf("empty output in API stream")
+	}
+	return out.String(), usage, nil
 }
 
-func buildUserContent(req *Request) string {
+// UserContent renders the step's input files as the user message sent to the model.
+func UserContent(req *Request) string {
 	var sb strings.Builder
 	// Sort keys for deterministic output
 	keys := make([]string, 0, len(req.InputFiles))
@@ -166,14 +237,7 @@ func buildUserContent(req *Request) string {
 	}
 	sort.Strings(keys)
 	for _, name := range keys {
-		content := req.InputFiles[name]
-		if label, isDiff := diffKeys[name]; isDiff {
-			if content != "" {
-				sb.WriteString(fmt.Sprintf("## %s\n\n```diff\n%s\n```\n\n", label, content))
-			}
-		} else {
-			sb.WriteString(fmt.Sprintf("## %s\n\n%s\n\n", name, content))
-		}
+		sb.WriteString(input.Render(name, req.InputFiles[name]))
 	}
 	return sb.String()
 }
@@ -14,9 +14,23 @@ type Executor interface {
 
 // Request carries all inputs for a step execution.
 type Request struct {
-	Step       types.Step
-	RunDir     string
-	InputFiles map[string]string // filename → content
+	Step         types.Step
+	RunDir       string
+	SystemPrompt string            // rendered prompt template, empty if the step has none
+	InputFiles   map[string]string // filename → content
+	// OnDelta, if set, receives output incrementally as it is produced.
+	// Executors that cannot stream simply never call it.
+	OnDelta func(delta string)
+	// Correction, if set, asks the executor to revise a previous attempt.
+	Correction *Correction
+	// Seed, if set, is passed to models that support deterministic sampling.
+	Seed *int
+}
+
+// Correction carries a rejected output and the feedback explaining why.
+type Correction struct {
+	PreviousOutput string
+	Feedback       string
 }
 
 // Result holds the output of a step execution.
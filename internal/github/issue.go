@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // Issue holds GitHub issue data.
@@ -12,15 +16,24 @@ type Issue struct {
 	Number int    `json:"number"`
 	Title  string `json:"title"`
 	Body   string `json:"body"`
+	State  string `json:"state"`
+	URL    string `json:"url"`
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"`
 	Labels []struct {
 		Name string `json:"name"`
 	} `json:"labels"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	Comments []Comment `json:"comments"`
 }
 
-// FetchIssue retrieves a GitHub issue via the gh CLI.
+// FetchIssue retrieves a GitHub issue, including its comments, via the gh CLI.
 func FetchIssue(ctx context.Context, number string) (*Issue, error) {
 	cmd := exec.CommandContext(ctx, "gh", "issue", "view", number,
-		"--json", "number,title,body,labels")
+		"--json", "number,title,body,state,url,author,labels,milestone,comments")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("gh issue view %s: %w", number, err)
@@ -32,3 +45,166 @@ func FetchIssue(ctx context.Context, number string) (*Issue, error) {
 	}
 	return &issue, nil
 }
+
+// Comment is a single comment on a GitHub issue.
+type Comment struct {
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	AuthorAssociation string `json:"authorAssociation"` // e.g. "OWNER", "MEMBER", "CONTRIBUTOR"
+	Body              string `json:"body"`
+	CreatedAt         string `json:"createdAt"`
+	ReactionGroups    []struct {
+		Content string `json:"content"`
+		Users   struct {
+			TotalCount int `json:"totalCount"`
+		} `json:"users"`
+	} `json:"reactionGroups"`
+}
+
+// Reactions returns the total number of reactions to the comment.
+func (c Comment) Reactions() int {
+	n := 0
+	for _, g := range c.ReactionGroups {
+		n += g.Users.TotalCount
+	}
+	return n
+}
+
+// ByMaintainer reports whether the comment author owns the repository or is
+// a member of its organization or a collaborator.
+func (c Comment) ByMaintainer() bool {
+	switch c.AuthorAssociation {
+	case "OWNER", "MEMBER", "COLLABORATOR":
+		return true
+	}
+	return false
+}
+
+// CommentFilter selects the comments of an issue worth passing on. With no
+// criterion set every comment is kept; otherwise a comment is kept if it
+// meets any of them. Comments by the issue author are always kept.
+type CommentFilter struct {
+	MinReactions    int  // keep comments with at least this many reactions; 0 = no criterion
+	MaintainersOnly bool // keep comments by maintainers (see Comment.ByMaintainer)
+}
+
+// Keep reports whether c, on an issue opened by issueAuthor, passes the filter.
+func (f CommentFilter) Keep(c Comment, issueAuthor string) bool {
+	if f.MinReactions <= 0 && !f.MaintainersOnly {
+		return true
+	}
+	return c.Author.Login == issueAuthor ||
+		(f.MinReactions > 0 && c.Reactions() >= f.MinReactions) ||
+		(f.MaintainersOnly && c.ByMaintainer())
+}
+
+// FetchComments retrieves the comments of a GitHub issue via the gh CLI.
+func FetchComments(ctx context.Context, number string) ([]Comment, error) {
+	cmd := exec.CommandContext(ctx, "gh", "issue", "view", number, "--json", "comments")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gh issue view %s: %w", number, err)
+	}
+
+	var resp struct {
+		Comments []Comment `json:"comments"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("parsing comments JSON: %w", err)
+	}
+	return resp.Comments, nil
+}
+
+// Reference is an issue or pull request mentioned in an issue.
+type Reference struct {
+	Repo        string // "owner/repo", empty for the current repository
+	Number      int
+	Title       string
+	State       string // "open" or "closed"
+	PullRequest bool
+}
+
+// String returns the reference as GitHub writes it, e.g. "#12" or
+// "owner/repo#12".
+func (r Reference) String() string {
+	return fmt.Sprintf("%s#%d", r.Repo, r.Number)
+}
+
+var (
+	// refURLRe matches links to issues and pull requests.
+	refURLRe = regexp.MustCompile(`https://github\.com/([\w.-]+/[\w.-]+)/(?:issues|pull)/(\d+)`)
+	// refShortRe matches "#12" and "owner/repo#12".
+	refShortRe = regexp.MustCompile(`(?:^|[^\w/&#])((?:[\w.-]+/[\w.-]+)?)#(\d+)\b`)
+)
+
+// FindReferences returns the issues and pull requests mentioned in texts, in
+// order of first mention and without duplicates. References into repo, the
+// current "owner/repo", are reported with an empty Repo, so that a link and
+// "#12" to the same issue count once. Code blocks and spans are skipped.
+func FindReferences(repo string, texts ...string) []Reference {
+	var refs []Reference
+	seen := map[string]bool{}
+	add := func(refRepo, number string) {
+		n, err := strconv.Atoi(number)
+		if err != nil || n <= 0 {
+			return
+		}
+		if strings.EqualFold(refRepo, repo) {
+			refRepo = ""
+		}
+		r := Reference{Repo: refRepo, Number: n}
+		if !seen[r.String()] {
+			seen[r.String()] = true
+			refs = append(refs, r)
+		}
+	}
+	for _, text := range texts {
+		text = stripCode(text)
+		// Blank out links so the short pattern does not match inside them,
+		// keeping offsets so mentions can be taken in order.
+		matches := refURLRe.FindAllStringSubmatchIndex(text, -1)
+		blanked := refURLRe.ReplaceAllStringFunc(text, func(m string) string {
+			return strings.Repeat(" ", len(m))
+		})
+		matches = append(matches, refShortRe.FindAllStringSubmatchIndex(blanked, -1)...)
+		sort.Slice(matches, func(i, j int) bool { return matches[i][4] < matches[j][4] })
+		for _, m := range matches {
+			add(text[m[2]:m[3]], text[m[4]:m[5]])
+		}
+	}
+	return refs
+}
+
+// codeRe matches fenced code blocks and inline code spans.
+var codeRe = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+
+func stripCode(text string) string {
+	return codeRe.ReplaceAllString(text, "")
+}
+
+// FetchReference fills in the title and state of r via the gh CLI.
+func FetchReference(ctx context.Context, r *Reference) error {
+	repo := r.Repo
+	if repo == "" {
+		repo = "{owner}/{repo}" // expanded by gh to the current repository
+	}
+	cmd := exec.CommandContext(ctx, "gh", "api", fmt.Sprintf("repos/%s/issues/%d", repo, r.Number))
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("gh api %s: %w", r, err)
+	}
+
+	var resp struct {
+		Title       string          `json:"title"`
+		State       string          `json:"state"`
+		PullRequest json.RawMessage `json:"pull_request"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return fmt.Errorf("parsing %s JSON: %w", r, err)
+	}
+	r.Title = resp.Title
+	r.State = resp.State
+	r.PullRequest = len(resp.PullRequest) > 0 && string(resp.PullRequest) != "null"
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package gitlab fetches issues from the GitLab REST API.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxNotePages bounds the pages of notes fetched for one issue.
+const maxNotePages = 10
+
+// Client calls the GitLab REST API (v4) of a GitLab instance.
+type Client struct {
+	BaseURL    string // e.g. https://gitlab.com or https://gitlab.example.com
+	Token      string // personal, project or group access token; empty for public projects
+	HTTPClient *http.Client
+}
+
+// Issue holds GitLab issue data.
+type Issue struct {
+	IID         int      `json:"iid"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	State       string   `json:"state"`
+	WebURL      string   `json:"web_url"`
+}
+
+// Note is a comment on a GitLab issue.
+type Note struct {
+	Author struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+	System    bool   `json:"system"` // generated by GitLab, e.g. "changed the description"
+}
+
+// FetchIssue retrieves issue iid of project, a path such as "group/repo".
+func (c *Client) FetchIssue(ctx context.Context, project, iid string) (*Issue, error) {
+	var issue Issue
+	if _, err := c.get(ctx, issuePath(project, iid), nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// FetchNotes retrieves the notes of issue iid of project, oldest first.
+// System notes are left out.
+func (c *Client) FetchNotes(ctx context.Context, project, iid string) ([]Note, error) {
+	var notes []Note
+	query := url.Values{"sort": {"asc"}, "order_by": {"created_at"}, "per_page": {"100"}}
+	for page := 1; page <= maxNotePages; page++ {
+		query.Set("page", fmt.Sprint(page))
+		var batch []Note
+		next, err := c.get(ctx, issuePath(project, iid)+"/notes", query, &batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range batch {
+			if !n.System {
+				notes = append(notes, n)
+			}
+		}
+		if next == "" {
+			break
+		}
+	}
+	return notes, nil
+}
+
+func issuePath(project, iid string) string {
+	return "/projects/" + url.PathEscape(project) + "/issues/" + url.PathEscape(iid)
+}
+
+// get decodes the JSON response of an API GET request into v and returns
+// the X-Next-Page header.
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) (string, error) {
+	endpoint := strings.TrimRight(c.BaseURL, "/") + "/api/v4" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating HTTP request: %w", err)
+	}
+	if c.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.Token)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message any    `json:"message"`
+			Error   string `json:"error"`
+		}
+		msg := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &apiErr) == nil {
+			if apiErr.Message != nil {
+				msg = fmt.Sprint(apiErr.Message)
+			} else if apiErr.Error != "" {
+				msg = apiErr.Error
+			}
+		}
+		return "", fmt.Errorf("GET %s: %s: %s", path, resp.Status, msg)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return "", fmt.Errorf("parsing %s JSON: %w", path, err)
+	}
+	return resp.Header.Get("X-Next-Page"), nil
+}
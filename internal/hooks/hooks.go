@@ -0,0 +1,118 @@
+// Package hooks runs user-defined shell commands around pipeline steps.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	vlog "github.com/futureCreator/vcoding/internal/log"
+)
+
+// Hook events.
+const (
+	PreStep   = "pre_step"
+	PostStep  = "post_step"
+	OnSuccess = "on_success"
+	OnFailure = "on_failure"
+)
+
+// DefaultTimeout bounds a single hook command.
+const DefaultTimeout = 5 * time.Minute
+
+// Runner executes hook commands with `sh -c`.
+type Runner struct {
+	LogPath string        // file receiving hook output, optional
+	Timeout time.Duration // per command; DefaultTimeout if zero
+}
+
+// Run executes cmds in order with env added to the process environment.
+// It stops at the first command that exits non-zero and returns its error.
+func (r *Runner) Run(ctx context.Context, event string, cmds []string, env map[string]string) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	environ := os.Environ()
+	environ = append(environ, "VCODING_HOOK="+event)
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		environ = append(environ, k+"="+env[k])
+	}
+
+	for _, c := range cmds {
+		if err := r.runOne(ctx, event, c, environ, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Runner) runOne(ctx context.Context, event, command string, environ []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = environ
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	start := time.Now()
+	err := cmd.Run()
+	output := strings.TrimSpace(out.String())
+
+	vlog.Debug("hook finished", "event", event, "command", command,
+		"duration", time.Since(start).Round(time.Millisecond), "err", err, "output", output)
+	r.appendLog(event, command, output, err)
+
+	if err != nil {
+		if output != "" {
+			return fmt.Errorf("%s hook %q: %w: %s", event, command, err, lastLine(output))
+		}
+		return fmt.Errorf("%s hook %q: %w", event, command, err)
+	}
+	return nil
+}
+
+// appendLog records a hook invocation and its output in LogPath.
+func (r *Runner) appendLog(event, command, output string, runErr error) {
+	if r.LogPath == "" {
+		return
+	}
+	f, err := os.OpenFile(r.LogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		vlog.Warn("failed to open hook log", "path", r.LogPath, "err", err)
+		return
+	}
+	defer f.Close()
+
+	status := "ok"
+	if runErr != nil {
+		status = runErr.Error()
+	}
+	fmt.Fprintf(f, "[%s] %s: %s (%s)\n", time.Now().Format(time.RFC3339), event, command, status)
+	if output != "" {
+		fmt.Fprintf(f, "%s\n", output)
+	}
+}
+
+func lastLine(s string) string {
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
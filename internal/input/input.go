@@ -0,0 +1,121 @@
+// Package input resolves virtual pipeline inputs such as "git:diff" or
+// "file:main.go#L10-80" through a registry of providers.
+package input
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/futureCreator/vcoding/internal/config"
+	"github.com/futureCreator/vcoding/internal/redact"
+)
+
+// Env is the run state available to providers.
+type Env struct {
+	RunDir     string
+	ProjectCtx string // pre-built project context markdown
+	GitDiff    string // staged + unstaged diff collected at run start
+	Config     *config.Config
+	IssueRef   string // GitHub issue number when the run was started with pick
+	Redactor   *redact.Redactor
+}
+
+// Provider resolves inputs named Prefix or Prefix:<arg>.
+type Provider struct {
+	Prefix string
+	// Resolve returns the content for arg ("" when the input is the bare prefix).
+	Resolve func(ctx context.Context, env *Env, arg string) (string, error)
+	// Label returns the section heading used when the content is sent to a model.
+	// Defaults to the input name.
+	Label func(arg string) string
+	// Fence returns the code fence language wrapping the content, "" for none.
+	Fence func(arg string) string
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+)
+
+// Register adds or replaces the provider for p.Prefix.
+func Register(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[p.Prefix] = p
+}
+
+// Lookup finds the provider for an input name, preferring the longest
+// matching prefix, and returns it with the argument after the prefix.
+func Lookup(name string) (Provider, string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	var best Provider
+	var arg string
+	found := false
+	for prefix, p := range providers {
+		var a string
+		switch {
+		case name == prefix:
+		case strings.HasPrefix(name, prefix+":"):
+			a = name[len(prefix)+1:]
+		default:
+			continue
+		}
+		if !found || len(prefix) > len(best.Prefix) {
+			best, arg, found = p, a, true
+		}
+	}
+	return best, arg, found
+}
+
+// IsVirtual reports whether name is handled by a registered provider.
+func IsVirtual(name string) bool {
+	_, _, ok := Lookup(name)
+	return ok
+}
+
+// Prefixes returns the registered provider prefixes in sorted order.
+func Prefixes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	result := make([]string, 0, len(providers))
+	for prefix := range providers {
+		result = append(result, prefix)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Resolve returns the content of a virtual input.
+func Resolve(ctx context.Context, env *Env, name string) (string, error) {
+	p, arg, ok := Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("unknown virtual input %q", name)
+	}
+	return p.Resolve(ctx, env, arg)
+}
+
+// Render formats an input as a markdown section for a model request.
+// Regular files are rendered under their name; virtual inputs use the
+// provider's label and fence and are omitted when empty.
+func Render(name, content string) string {
+	label, fence := name, ""
+	if p, arg, ok := Lookup(name); ok {
+		if content == "" {
+			return ""
+		}
+		if p.Label != nil {
+			label = p.Label(arg)
+		}
+		if p.Fence != nil {
+			fence = p.Fence(arg)
+		}
+	}
+	if fence == "" {
+		return fmt.Sprintf("## %s\n\n%s\n\n", label, content)
+	}
+	return fmt.Sprintf("## %s\n\n```%s\n%s\n```\n\n", label, fence, content)
+}
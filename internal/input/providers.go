@@ -0,0 +1,363 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/futureCreator/vcoding/internal/config"
+	"github.com/futureCreator/vcoding/internal/github"
+	"github.com/futureCreator/vcoding/internal/project"
+)
+
+// defaultLogCommits is the number of commits rendered by "git:log".
+const defaultLogCommits = 20
+
+func init() {
+	Register(Provider{
+		Prefix: "project:context",
+		Resolve: func(ctx context.Context, env *Env, arg string) (string, error) {
+			return env.ProjectCtx, nil
+		},
+	})
+
+	Register(Provider{
+		Prefix:  "project:map",
+		Resolve: resolveProjectMap,
+		Label: func(arg string) string {
+			if arg == "" {
+				return "Repository map"
+			}
+			return "Repository map of " + arg
+		},
+		Fence: func(string) string { return "text" },
+	})
+
+	Register(Provider{
+		Prefix:  "project:guidelines",
+		Resolve: resolveGuidelines,
+		Label:   func(string) string { return "Project guidelines" },
+	})
+
+	Register(Provider{
+		Prefix:  "git:diff",
+		Resolve: resolveGitDiff,
+		Label: func(arg string) string {
+			if arg == "" {
+				return "git diff"
+			}
+			return "git diff " + arg + "...HEAD"
+		},
+		Fence: func(string) string { return "diff" },
+	})
+
+	Register(Provider{
+		Prefix: "git:log",
+		Resolve: func(ctx context.Context, env *Env, arg string) (string, error) {
+			n := defaultLogCommits
+			if arg != "" {
+				v, err := strconv.Atoi(arg)
+				if err != nil || v <= 0 {
+					return "", fmt.Errorf("git:log: invalid commit count %q", arg)
+				}
+				n = v
+			}
+			log, err := project.Log(n)
+			if err != nil {
+				return "", err
+			}
+			return env.Redactor.Redact("git:log", "", log)
+		},
+		Label: func(string) string { return "Recent commits" },
+		Fence: func(string) string { return "text" },
+	})
+
+	Register(Provider{
+		Prefix: "git:tree",
+		Resolve: func(ctx context.Context, env *Env, arg string) (string, error) {
+			files, err := project.TrackedFiles()
+			if err != nil {
+				return "", err
+			}
+			return env.Redactor.Redact("git:tree", "", formatTree(files))
+		},
+		Label: func(string) string { return "Repository tree" },
+		Fence: func(string) string { return "text" },
+	})
+
+	Register(Provider{
+		Prefix:  "glob",
+		Resolve: resolveGlob,
+		Label:   func(arg string) string { return "Files matching " + arg },
+	})
+
+	Register(Provider{
+		Prefix:  "file",
+		Resolve: resolveFile,
+		Label: func(arg string) string {
+			p, from, to, _ := parseLineRange(arg)
+			if from == 0 {
+				return p
+			}
+			return fmt.Sprintf("%s (lines %d-%d)", p, from, to)
+		},
+		Fence: func(arg string) string {
+			p, _, _, _ := parseLineRange(arg)
+			return fenceLanguage(p)
+		},
+	})
+
+	Register(Provider{
+		Prefix:  "run",
+		Resolve: resolveRunArtifact,
+		Label: func(arg string) string {
+			id, artifact, _ := strings.Cut(arg, "/")
+			return fmt.Sprintf("%s (run %s)", artifact, id)
+		},
+	})
+
+	Register(Provider{
+		Prefix: "env",
+		Resolve: func(ctx context.Context, env *Env, arg string) (string, error) {
+			v, ok := os.LookupEnv(arg)
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", arg)
+			}
+			return env.Redactor.Redact("env:"+arg, "", v)
+		},
+		Label: func(arg string) string { return "$" + arg },
+		Fence: func(string) string { return "text" },
+	})
+
+	Register(Provider{
+		Prefix:  "issue:comments",
+		Resolve: resolveIssueComments,
+		Label:   func(string) string { return "Issue comments" },
+	})
+}
+
+// resolveProjectMap renders the repository map of the working directory (or
+// the run's scope), or with an argument, of that subdirectory, within
+// project_context.map_tokens.
+func resolveProjectMap(ctx context.Context, env *Env, arg string) (string, error) {
+	cfg := &config.ProjectCtxConfig{RespectGitignore: true}
+	if env.Config != nil {
+		cfg = &env.Config.ProjectContext
+	}
+	root := "."
+	if cfg.Scope != "" {
+		root = cfg.Scope
+	}
+	if arg != "" {
+		root = arg
+		if info, err := os.Stat(filepath.FromSlash(root)); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("project:map: %s is not a directory", arg)
+		}
+	}
+	return project.RepoMap(cfg, root, cfg.MapTokens)
+}
+
+// resolveGuidelines concatenates the project guidance files found under
+// project_context.guidelines, each under its path.
+func resolveGuidelines(ctx context.Context, env *Env, arg string) (string, error) {
+	if env.Config == nil {
+		return "", nil
+	}
+	var sb strings.Builder
+	for _, p := range project.GuidelineFiles(&env.Config.ProjectContext) {
+		data, err := os.ReadFile(filepath.FromSlash(p))
+		if err != nil {
+			return "", fmt.Errorf("project:guidelines: %w", err)
+		}
+		content, err := env.Redactor.Redact("project:guidelines", p, strings.TrimSpace(string(data)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "### %s\n\n%s\n\n", p, content)
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// resolveGitDiff returns the diff collected at run start, or with an argument,
+// the diff against that ref, limited to the run's scope. The argument "base"
+// means github.base_branch.
+// Secrets in the diff against a ref are redacted here; the diff collected at
+// run start already is.
+func resolveGitDiff(ctx context.Context, env *Env, arg string) (string, error) {
+	if arg == "" {
+		return env.GitDiff, nil
+	}
+	if arg == "base" && env.Config != nil && env.Config.GitHub.BaseBranch != "" {
+		arg = env.Config.GitHub.BaseBranch
+	}
+	scope := ""
+	if env.Config != nil {
+		scope = env.Config.ProjectContext.Scope
+	}
+	diff, err := project.DiffAgainst(arg, scope)
+	if err != nil {
+		return "", err
+	}
+	return env.Redactor.Redact("git:diff:"+arg, "", diff)
+}
+
+// resolveGlob renders the eligible files matching the pattern (see
+// project.Glob), bounded by the project_context limits, with secrets
+// redacted.
+func resolveGlob(ctx context.Context, env *Env, pattern string) (string, error) {
+	cfg := &config.ProjectCtxConfig{RespectGitignore: true}
+	if env.Config != nil {
+		cfg = &env.Config.ProjectContext
+	}
+	entries, err := project.Glob(cfg, pattern)
+	if err != nil {
+		return "", fmt.Errorf("glob:%s: %w", pattern, err)
+	}
+
+	var sb strings.Builder
+	for _, e := range entries {
+		content, err := env.Redactor.Redact("glob:"+pattern, e.Path, e.Content)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "### %s\n\n```%s\n%s\n```\n\n", e.Path, fenceLanguage(e.Path), content)
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// resolveFile returns a file or a line range of it ("path#L10-80" or "path#L10"),
+// with secrets redacted. The path must stay inside the project.
+func resolveFile(ctx context.Context, env *Env, arg string) (string, error) {
+	p, from, to, err := parseLineRange(arg)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsLocal(p) {
+		return "", fmt.Errorf("file:%s: path must be relative to the project root and stay inside it", arg)
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", fmt.Errorf("file:%s: %w", arg, err)
+	}
+	if from == 0 {
+		return env.Redactor.Redact("file:"+arg, filepath.ToSlash(p), string(data))
+	}
+	lines := strings.Split(string(data), "\n")
+	if from > len(lines) {
+		return "", fmt.Errorf("file:%s: file has only %d lines", arg, len(lines))
+	}
+	if to > len(lines) {
+		to = len(lines)
+	}
+	return env.Redactor.Redact("file:"+arg, filepath.ToSlash(p), strings.Join(lines[from-1:to], "\n"))
+}
+
+// parseLineRange splits "path#L10-80" into its path and 1-based inclusive
+// line range. from is 0 when no range is given.
+func parseLineRange(arg string) (p string, from, to int, err error) {
+	p, frag, ok := strings.Cut(arg, "#L")
+	if !ok {
+		return arg, 0, 0, nil
+	}
+	start, end, isRange := strings.Cut(frag, "-")
+	end = strings.TrimPrefix(end, "L")
+	if from, err = strconv.Atoi(start); err != nil || from < 1 {
+		return p, 0, 0, fmt.Errorf("file:%s: invalid line range", arg)
+	}
+	to = from
+	if isRange {
+		if to, err = strconv.Atoi(end); err != nil || to < from {
+			return p, 0, 0, fmt.Errorf("file:%s: invalid line range", arg)
+		}
+	}
+	return p, from, to, nil
+}
+
+// resolveRunArtifact reads an artifact from another run: "run:<id>/<artifact>",
+// with secrets redacted.
+func resolveRunArtifact(ctx context.Context, env *Env, arg string) (string, error) {
+	id, artifact, ok := strings.Cut(arg, "/")
+	if !ok || id == "" || artifact == "" {
+		return "", fmt.Errorf("run:%s: expected run:<id>/<artifact>", arg)
+	}
+	if strings.Contains(id, "..") || strings.Contains(artifact, "..") {
+		return "", fmt.Errorf("run:%s: invalid path", arg)
+	}
+	data, err := os.ReadFile(filepath.Join(".vcoding", "runs", id, artifact))
+	if err != nil {
+		return "", fmt.Errorf("run:%s: %w", arg, err)
+	}
+	return env.Redactor.Redact("run:"+arg, path.Join(".vcoding/runs", id, artifact), string(data))
+}
+
+// resolveIssueComments renders the comments of the issue the run was started
+// from. Runs not started from an issue have no comments.
+func resolveIssueComments(ctx context.Context, env *Env, arg string) (string, error) {
+	if env.IssueRef == "" {
+		return "", nil
+	}
+	comments, err := github.FetchComments(ctx, env.IssueRef)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, c := range comments {
+		fmt.Fprintf(&sb, "### @%s (%s)\n\n%s\n\n", c.Author.Login, c.CreatedAt, strings.TrimSpace(c.Body))
+	}
+	return env.Redactor.Redact("issue:comments", "", strings.TrimRight(sb.String(), "\n"))
+}
+
+// formatTree renders sorted slash-separated paths as an indented tree.
+func formatTree(paths []string) string {
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+
+	var sb strings.Builder
+	var prev []string
+	for _, p := range sorted {
+		parts := strings.Split(p, "/")
+		dirs := parts[:len(parts)-1]
+		common := 0
+		for common < len(dirs) && common < len(prev) && dirs[common] == prev[common] {
+			common++
+		}
+		for i := common; i < len(dirs); i++ {
+			fmt.Fprintf(&sb, "%s%s/\n", strings.Repeat("  ", i), dirs[i])
+		}
+		fmt.Fprintf(&sb, "%s%s\n", strings.Repeat("  ", len(dirs)), parts[len(parts)-1])
+		prev = dirs
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// fenceLanguages maps file extensions to markdown code fence languages.
+var fenceLanguages = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".ts":   "typescript",
+	".tsx":  "tsx",
+	".js":   "javascript",
+	".jsx":  "jsx",
+	".rs":   "rust",
+	".java": "java",
+	".rb":   "ruby",
+	".sh":   "bash",
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".json": "json",
+	".toml": "toml",
+	".md":   "markdown",
+	".sql":  "sql",
+}
+
+func fenceLanguage(p string) string {
+	if lang, ok := fenceLanguages[strings.ToLower(path.Ext(p))]; ok {
+		return lang
+	}
+	return "text"
+}
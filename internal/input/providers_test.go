@@ -0,0 +1,31 @@
+package input
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestResolveFileStaysInProject(t *testing.T) {
+	tests := []struct {
+		arg     string
+		wantErr string
+	}{
+		{"providers.go#L1", ""},
+		{"../input/providers.go", "stay inside"},
+		{"/etc/passwd", "stay inside"},
+		{"../../.ssh/id_rsa#L1-5", "stay inside"},
+		{"./providers.go#L1", ""},
+	}
+	for _, tt := range tests {
+		got, err := resolveFile(context.Background(), &Env{}, tt.arg)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("resolveFile(%q): %v", tt.arg, err)
+		case tt.wantErr == "" && got != "package input":
+			t.Errorf("resolveFile(%q) = %q, want the first line", tt.arg, got)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("resolveFile(%q) error = %v, want it to contain %q", tt.arg, err, tt.wantErr)
+		}
+	}
+}
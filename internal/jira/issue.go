@@ -0,0 +1,163 @@
+// Package jira fetches issues from the Jira REST API and converts their
+// rich text to markdown.
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client calls the Jira REST API of a Jira Cloud site or Jira Server/Data
+// Center instance.
+type Client struct {
+	BaseURL    string // e.g. https://example.atlassian.net
+	Email      string // account email for Jira Cloud basic auth; empty to send Token as a bearer token
+	Token      string // API token (Cloud) or personal access token (Server/Data Center)
+	APIVersion string // "3" (default, descriptions in ADF) or "2" (wiki markup)
+	HTTPClient *http.Client
+}
+
+// Issue holds Jira issue data with rich text converted to markdown.
+type Issue struct {
+	Key                string
+	Summary            string
+	Description        string
+	AcceptanceCriteria string
+	Type               string
+	Priority           string
+	Status             string
+	Labels             []string
+}
+
+// FetchIssue retrieves issue key (e.g. "PROJ-123"). acField names the
+// custom field holding acceptance criteria, e.g. "customfield_10100"; empty
+// if there is none.
+func (c *Client) FetchIssue(ctx context.Context, key, acField string) (*Issue, error) {
+	fields := []string{"summary", "description", "issuetype", "priority", "status", "labels"}
+	if acField != "" {
+		fields = append(fields, acField)
+	}
+	var resp struct {
+		Key    string                     `json:"key"`
+		Fields map[string]json.RawMessage `json:"fields"`
+	}
+	path := "/issue/" + url.PathEscape(key)
+	if err := c.get(ctx, path, url.Values{"fields": {strings.Join(fields, ",")}}, &resp); err != nil {
+		return nil, err
+	}
+
+	issue := &Issue{Key: resp.Key}
+	var named struct {
+		Name string `json:"name"`
+	}
+	for name, raw := range resp.Fields {
+		if string(raw) == "null" {
+			continue
+		}
+		var err error
+		switch name {
+		case "summary":
+			err = json.Unmarshal(raw, &issue.Summary)
+		case "description":
+			issue.Description, err = richText(raw)
+		case acField:
+			issue.AcceptanceCriteria, err = richText(raw)
+		case "issuetype", "priority", "status":
+			named.Name = ""
+			err = json.Unmarshal(raw, &named)
+			switch name {
+			case "issuetype":
+				issue.Type = named.Name
+			case "priority":
+				issue.Priority = named.Name
+			case "status":
+				issue.Status = named.Name
+			}
+		case "labels":
+			err = json.Unmarshal(raw, &issue.Labels)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing field %s of %s: %w", name, key, err)
+		}
+	}
+	return issue, nil
+}
+
+// richText converts a rich text field to markdown: an ADF document (API v3)
+// or wiki markup (API v2 and text custom fields).
+func richText(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return WikiToMarkdown(s), nil
+	}
+	var doc Node
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", err
+	}
+	return ADFToMarkdown(&doc), nil
+}
+
+// get decodes the JSON response of an API GET request into v.
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) error {
+	version := c.APIVersion
+	if version == "" {
+		version = "3"
+	}
+	endpoint := strings.TrimRight(c.BaseURL, "/") + "/rest/api/" + version + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("creating HTTP request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.Email != "":
+		req.SetBasicAuth(c.Email, c.Token)
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			ErrorMessages []string          `json:"errorMessages"`
+			Errors        map[string]string `json:"errors"`
+		}
+		msg := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &apiErr) == nil {
+			msgs := apiErr.ErrorMessages
+			for field, m := range apiErr.Errors {
+				msgs = append(msgs, field+": "+m)
+			}
+			if len(msgs) > 0 {
+				msg = strings.Join(msgs, "; ")
+			}
+		}
+		return fmt.Errorf("GET %s: %s: %s", path, resp.Status, msg)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parsing %s JSON: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newJiraServer serves PROJ-1 on API v3 (ADF description) and PROJ-2 on API
+// v2 (wiki markup description); other issues do not exist.
+func newJiraServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "me@example.com" || token != "secret" {
+			t.Errorf("basic auth = %q, %q, %v", user, token, ok)
+		}
+		if fields := r.URL.Query().Get("fields"); !strings.Contains(fields, "customfield_10100") {
+			t.Errorf("fields = %q, want the acceptance criteria field", fields)
+		}
+		issue := map[string]any{
+			"summary":           "Export reports",
+			"issuetype":         map[string]string{"name": "Story"},
+			"priority":          map[string]string{"name": "High"},
+			"status":            map[string]string{"name": "To Do"},
+			"labels":            []string{"ui"},
+			"customfield_10100": "* exports *CSV*",
+		}
+		switch r.URL.Path {
+		case "/rest/api/3/issue/PROJ-1":
+			issue["description"] = map[string]any{
+				"type": "doc",
+				"content": []any{map[string]any{
+					"type": "paragraph",
+					"content": []any{
+						map[string]any{"type": "text", "text": "Users want "},
+						map[string]any{"type": "text", "text": "exports", "marks": []any{map[string]string{"type": "strong"}}},
+					},
+				}},
+			}
+			json.NewEncoder(w).Encode(map[string]any{"key": "PROJ-1", "fields": issue})
+		case "/rest/api/2/issue/PROJ-2":
+			issue["description"] = "h2. Context\nUsers want _exports_."
+			issue["priority"] = nil
+			json.NewEncoder(w).Encode(map[string]any{"key": "PROJ-2", "fields": issue})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{
+				"errorMessages": []string{"Issue does not exist or you do not have permission to see it."},
+				"errors":        map[string]string{},
+			})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchIssue(t *testing.T) {
+	srv := newJiraServer(t)
+	tests := []struct {
+		key, version, description string
+		priority                  string
+	}{
+		{"PROJ-1", "3", "Users want **exports**", "High"},
+		{"PROJ-2", "2", "## Context\nUsers want *exports*.", ""},
+	}
+	for _, tt := range tests {
+		t.Run("v"+tt.version, func(t *testing.T) {
+			c := &Client{BaseURL: srv.URL, Email: "me@example.com", Token: "secret", APIVersion: tt.version, HTTPClient: srv.Client()}
+			issue, err := c.FetchIssue(context.Background(), tt.key, "customfield_10100")
+			if err != nil {
+				t.Fatalf("FetchIssue: %v", err)
+			}
+			if issue.Key != tt.key || issue.Summary != "Export reports" || issue.Type != "Story" || issue.Status != "To Do" {
+				t.Errorf("got %+v", issue)
+			}
+			if issue.Priority != tt.priority {
+				t.Errorf("Priority = %q, want %q", issue.Priority, tt.priority)
+			}
+			if issue.Description != tt.description {
+				t.Errorf("Description = %q, want %q", issue.Description, tt.description)
+			}
+			if want := "- exports **CSV**"; issue.AcceptanceCriteria != want {
+				t.Errorf("AcceptanceCriteria = %q, want %q", issue.AcceptanceCriteria, want)
+			}
+			if len(issue.Labels) != 1 || issue.Labels[0] != "ui" {
+				t.Errorf("Labels = %v", issue.Labels)
+			}
+		})
+	}
+}
+
+func TestFetchIssueError(t *testing.T) {
+	srv := newJiraServer(t)
+	c := &Client{BaseURL: srv.URL, Email: "me@example.com", Token: "secret", HTTPClient: srv.Client()}
+	_, err := c.FetchIssue(context.Background(), "PROJ-404", "customfield_10100")
+	if err == nil {
+		t.Fatal("FetchIssue succeeded, want error")
+	}
+	for _, want := range []string{"404", "Issue does not exist or you do not have permission to see it."} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to contain %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package jira
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Node is a node of an Atlassian Document Format (ADF) document, the rich
+// text format of Jira Cloud REST API v3.
+type Node struct {
+	Type    string         `json:"type"`
+	Text    string         `json:"text"`
+	Attrs   map[string]any `json:"attrs"`
+	Marks   []Mark         `json:"marks"`
+	Content []Node         `json:"content"`
+}
+
+// Mark is inline formatting applied to an ADF text node.
+type Mark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs"`
+}
+
+// ADFToMarkdown renders an ADF document as markdown. Unknown nodes render
+// their content, so that no text is lost.
+func ADFToMarkdown(doc *Node) string {
+	var sb strings.Builder
+	writeBlocks(&sb, doc.Content, "")
+	return strings.TrimSpace(sb.String())
+}
+
+// writeBlocks renders block nodes, each line prefixed with indent, separated
+// by blank lines.
+func writeBlocks(sb *strings.Builder, nodes []Node, indent string) {
+	for i, n := range nodes {
+		if i > 0 {
+			sb.WriteString(strings.TrimRight(indent, " ") + "\n")
+		}
+		writeBlock(sb, n, indent)
+	}
+}
+
+func writeBlock(sb *strings.Builder, n Node, indent string) {
+	switch n.Type {
+	case "paragraph":
+		writeLines(sb, inline(n.Content), indent)
+	case "heading":
+		level := int(attrNumber(n.Attrs, "level"))
+		if level < 1 || level > 6 {
+			level = 2
+		}
+		writeLines(sb, strings.Repeat("#", level)+" "+inline(n.Content), indent)
+	case "bulletList", "orderedList":
+		for i, item := range n.Content {
+			marker := "- "
+			if n.Type == "orderedList" {
+				marker = fmt.Sprintf("%d. ", i+1)
+			}
+			writeListItem(sb, item, indent, marker)
+		}
+	case "taskList":
+		for _, item := range n.Content {
+			marker := "- [ ] "
+			if attrString(item.Attrs, "state") == "DONE" {
+				marker = "- [x] "
+			}
+			writeListItem(sb, Node{Content: []Node{{Type: "paragraph", Content: item.Content}}}, indent, marker)
+		}
+	case "codeBlock":
+		writeLines(sb, "```"+attrString(n.Attrs, "language")+"\n"+plainText(n.Content)+"\n```", indent)
+	case "blockquote":
+		writeBlocks(sb, n.Content, indent+"> ")
+	case "panel":
+		if kind := attrString(n.Attrs, "panelType"); kind != "" {
+			writeLines(sb, "**"+strings.ToUpper(kind[:1])+kind[1:]+":**", indent+"> ")
+			sb.WriteString(strings.TrimRight(indent+"> ", " ") + "\n")
+		}
+		writeBlocks(sb, n.Content, indent+"> ")
+	case "rule":
+		writeLines(sb, "---", indent)
+	case "table":
+		writeTable(sb, n, indent)
+	case "mediaSingle", "mediaGroup", "media":
+		writeLines(sb, "[attachment]", indent)
+	case "text", "hardBreak", "mention", "emoji", "inlineCard", "status", "date":
+		writeLines(sb, inline([]Node{n}), indent)
+	default:
+		writeBlocks(sb, n.Content, indent)
+	}
+}
+
+// writeListItem renders a list item: its first paragraph after marker, the
+// rest of its blocks (including nested lists) indented below.
+func writeListItem(sb *strings.Builder, item Node, indent, marker string) {
+	nested := indent + strings.Repeat(" ", len(marker))
+	blocks := item.Content
+	if len(blocks) == 0 || blocks[0].Type != "paragraph" {
+		sb.WriteString(indent + strings.TrimRight(marker, " ") + "\n")
+		writeBlocks(sb, blocks, nested)
+		return
+	}
+	lines := strings.Split(inline(blocks[0].Content), "\n")
+	sb.WriteString(indent + marker + lines[0] + "\n")
+	for _, l := range lines[1:] {
+		sb.WriteString(nested + l + "\n")
+	}
+	for _, b := range blocks[1:] {
+		writeBlock(sb, b, nested)
+	}
+}
+
+func writeTable(sb *strings.Builder, table Node, indent string) {
+	for i, row := range table.Content {
+		cells := make([]string, len(row.Content))
+		for j, cell := range row.Content {
+			var cb strings.Builder
+			writeBlocks(&cb, cell.Content, "")
+			text := strings.TrimSpace(cb.String())
+			text = strings.ReplaceAll(text, "|", `\|`)
+			cells[j] = strings.ReplaceAll(text, "\n", "<br>")
+		}
+		sb.WriteString(indent + "| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString(indent + strings.Repeat("| --- ", len(cells)) + "|\n")
+		}
+	}
+}
+
+// writeLines writes text with every line prefixed with indent.
+func writeLines(sb *strings.Builder, text, indent string) {
+	for _, l := range strings.Split(text, "\n") {
+		sb.WriteString(strings.TrimRight(indent+l, " ") + "\n")
+	}
+}
+
+// inline renders inline nodes as markdown.
+func inline(nodes []Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Type {
+		case "text":
+			sb.WriteString(applyMarks(n.Text, n.Marks))
+		case "hardBreak":
+			sb.WriteString("\n")
+		case "mention":
+			name := attrString(n.Attrs, "text")
+			if !strings.HasPrefix(name, "@") {
+				name = "@" + name
+			}
+			sb.WriteString(name)
+		case "emoji":
+			if text := attrString(n.Attrs, "text"); text != "" {
+				sb.WriteString(text)
+			} else {
+				sb.WriteString(attrString(n.Attrs, "shortName"))
+			}
+		case "inlineCard", "blockCard":
+			sb.WriteString("<" + attrString(n.Attrs, "url") + ">")
+		case "status":
+			sb.WriteString("[" + attrString(n.Attrs, "text") + "]")
+		case "date":
+			sb.WriteString(attrString(n.Attrs, "timestamp"))
+		default:
+			sb.WriteString(inline(n.Content))
+		}
+	}
+	return sb.String()
+}
+
+func applyMarks(text string, marks []Mark) string {
+	// Code spans cannot hold other formatting.
+	for _, m := range marks {
+		if m.Type == "code" {
+			return "`" + text + "`"
+		}
+	}
+	for _, m := range marks {
+		switch m.Type {
+		case "strong":
+			text = "**" + text + "**"
+		case "em":
+			text = "*" + text + "*"
+		case "strike":
+			text = "~~" + text + "~~"
+		case "link":
+			text = "[" + text + "](" + attrString(m.Attrs, "href") + ")"
+		}
+	}
+	return text
+}
+
+// plainText concatenates the text of nodes, ignoring formatting.
+func plainText(nodes []Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		if n.Type == "hardBreak" {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(n.Text)
+		sb.WriteString(plainText(n.Content))
+	}
+	return sb.String()
+}
+
+func attrString(attrs map[string]any, key string) string {
+	if s, ok := attrs[key].(string); ok {
+		return s
+	}
+	return ""
+}
+
+func attrNumber(attrs map[string]any, key string) float64 {
+	n, _ := attrs[key].(float64)
+	return n
+}
+
+// Wiki markup patterns, applied to lines outside code blocks.
+var (
+	wikiHeadingRe  = regexp.MustCompile(`^h([1-6])\.\s+(.*)$`)
+	wikiListRe     = regexp.MustCompile(`^([*#-]+)\s+(.*)$`)
+	wikiQuoteRe    = regexp.MustCompile(`^bq\.\s*(.*)$`)
+	wikiCodeRe     = regexp.MustCompile(`^\{(code|noformat)(?::([^}]*))?\}(.*)$`)
+	wikiTableRe    = regexp.MustCompile(`^\|\|?(.*?)\|\|?\s*$`)
+	wikiLinkRe     = regexp.MustCompile(`\[([^|\]]+)\|([^\]]+)\]`)
+	wikiBareLinkRe = regexp.MustCompile(`\[((?:https?|mailto):[^\]]+)\]`)
+	wikiMonoRe     = regexp.MustCompile(`\{\{(.+?)\}\}`)
+	wikiBoldRe     = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*($|[^\w*])`)
+	wikiItalicRe   = regexp.MustCompile(`(^|[^\w_])_([^_\s](?:[^_]*[^_\s])?)_($|[^\w_])`)
+	wikiStrikeRe   = regexp.MustCompile(`(^|[^\w-])-([^-\s](?:[^-]*[^-\s])?)-($|[^\w-])`)
+	wikiMentionRe  = regexp.MustCompile(`\[~([^\]]+)\]`)
+	wikiColorRe    = regexp.MustCompile(`\{color(?::[^}]*)?\}`)
+)
+
+// WikiToMarkdown converts Jira wiki markup (the description format of REST
+// API v2 and Jira Server) to markdown. Constructs without a markdown
+// equivalent are left as they are.
+func WikiToMarkdown(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	var out []string
+	fence := ""    // closing tag of the open {code} or {noformat} block
+	quote := false // inside {quote}
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if before, ok := strings.CutSuffix(trimmed, fence); ok {
+				if before != "" {
+					out = append(out, before)
+				}
+				out = append(out, "```")
+				fence = ""
+				continue
+			}
+			out = append(out, line)
+			continue
+		}
+		if m := wikiCodeRe.FindStringSubmatch(trimmed); m != nil {
+			lang := ""
+			if m[1] == "code" {
+				// {code:java} or {code:title=Foo.java|language=java}
+				for _, opt := range strings.Split(m[2], "|") {
+					if v, ok := strings.CutPrefix(opt, "language="); ok {
+						lang = v
+					} else if !strings.Contains(opt, "=") {
+						lang = opt
+					}
+				}
+			}
+			tag := "{" + m[1] + "}"
+			out = append(out, "```"+lang)
+			if rest, ok := strings.CutSuffix(m[3], tag); ok {
+				// Single-line block.
+				if rest != "" {
+					out = append(out, rest)
+				}
+				out = append(out, "```")
+				continue
+			}
+			if m[3] != "" {
+				out = append(out, m[3])
+			}
+			fence = tag
+			continue
+		}
+		// {quote} toggles a block quote and may appear anywhere in a line,
+		// e.g. "{quote}text{quote}".
+		parts := strings.Split(trimmed, "{quote}")
+		for i, part := range parts {
+			if i > 0 {
+				quote = !quote
+			}
+			part = strings.TrimSpace(part)
+			if part == "" && len(parts) > 1 {
+				continue
+			}
+			md := wikiLine(part)
+			if quote {
+				md = strings.TrimRight("> "+strings.ReplaceAll(md, "\n", "\n> "), " ")
+			}
+			out = append(out, md)
+		}
+	}
+	if fence != "" {
+		out = append(out, "```")
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// wikiLine converts one line of wiki markup outside code blocks.
+func wikiLine(line string) string {
+	if strings.HasPrefix(line, "|") {
+		if m := wikiTableRe.FindStringSubmatch(line); m != nil {
+			header := strings.HasPrefix(line, "||")
+			sep := "|"
+			if header {
+				sep = "||"
+			}
+			cells := strings.Split(m[1], sep)
+			for i, c := range cells {
+				cells[i] = wikiInline(strings.TrimSpace(c))
+			}
+			row := "| " + strings.Join(cells, " | ") + " |"
+			if header {
+				return row + "\n" + strings.Repeat("| --- ", len(cells)) + "|"
+			}
+			return row
+		}
+	}
+
+	if m := wikiHeadingRe.FindStringSubmatch(line); m != nil {
+		return strings.Repeat("#", int(m[1][0]-'0')) + " " + wikiInline(m[2])
+	}
+	if m := wikiQuoteRe.FindStringSubmatch(line); m != nil {
+		return "> " + wikiInline(m[1])
+	}
+	if line == "----" {
+		return "---"
+	}
+	if m := wikiListRe.FindStringSubmatch(line); m != nil && !(m[1] == "-" && strings.HasPrefix(m[2], "-")) {
+		// Nested items are indented by the width of their parents' markers,
+		// so that they nest under "1. " as well as "- ".
+		indent := ""
+		marker := ""
+		for _, c := range m[1] {
+			indent += strings.Repeat(" ", len(marker))
+			marker = "- "
+			if c == '#' {
+				marker = "1. "
+			}
+		}
+		return indent + marker + wikiInline(m[2])
+	}
+	return wikiInline(line)
+}
+
+// wikiInline converts inline wiki formatting.
+func wikiInline(s string) string {
+	// Protect monospace spans from the other rules.
+	var code []string
+	s = wikiMonoRe.ReplaceAllStringFunc(s, func(m string) string {
+		code = append(code, "`"+m[2:len(m)-2]+"`")
+		return fmt.Sprintf("\x00%d\x00", len(code)-1)
+	})
+	s = wikiColorRe.ReplaceAllString(s, "")
+	s = wikiMentionRe.ReplaceAllString(s, "@$1")
+	s = wikiLinkRe.ReplaceAllString(s, "[$1]($2)")
+	s = wikiBareLinkRe.ReplaceAllString(s, "<$1>")
+	s = wikiBoldRe.ReplaceAllString(s, "$1**$2**$3")
+	s = wikiItalicRe.ReplaceAllString(s, "$1*$2*$3")
+	s = wikiStrikeRe.ReplaceAllString(s, "$1~~$2~~$3")
+	for i, c := range code {
+		s = strings.Replace(s, fmt.Sprintf("\x00%d\x00", i), c, 1)
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+package jira
+
+import "testing"
+
+func TestWikiToMarkdown(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"heading", "h2. Goal", "## Goal"},
+		{"inline", "*bold* _em_ {{code()}} -gone- [docs|http://x.y]", "**bold** *em* `code()` ~~gone~~ [docs](http://x.y)"},
+		{"hyphenated words", "a well-known x - y case", "a well-known x - y case"},
+		{"bq", "bq. quoted", "> quoted"},
+		{"quote block", "{quote}\nfirst\nsecond\n{quote}\nafter", "> first\n> second\nafter"},
+		{"inline quote", "{quote}quoted{quote}", "> quoted"},
+		{"nested bullets", "* a\n** b", "- a\n  - b"},
+		{"nested ordered", "# a\n## b\n#* c", "1. a\n   1. b\n   - c"},
+		{"code", "{code:go}\nx := *y*\n{code}", "```go\nx := *y*\n```"},
+		{"table", "||A||B||\n|1|2|", "| A | B |\n| --- | --- |\n| 1 | 2 |"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WikiToMarkdown(tt.in); got != tt.want {
+				t.Errorf("WikiToMarkdown(%q) =\n%q\nwant\n%q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package pipeline
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/futureCreator/vcoding/internal/run"
+)
+
+// Default input priorities for token budget truncation; higher priorities
+// are kept first. Steps override them with priorities:.
+const (
+	priorityTicket     = 100
+	priorityGuidelines = 90
+	priorityPlan       = 80
+	priorityReview     = 70
+	priorityDefault    = 50
+	priorityContext    = 20
+)
+
+const truncationNote = "\n\n[... truncated due to token limit ...]"
+
+// fileSectionRe matches the "### path" header of a file in a file list
+// input, as written by project.FormatContext and glob:.
+var fileSectionRe = regexp.MustCompile("(?m)^### (\\S+)\\n\\n```")
+
+// InputPriority returns the truncation priority of an input: the step's
+// override if any, else ticket > guidelines > plan > review > other inputs >
+// project context.
+func InputPriority(name string, overrides map[string]int) int {
+	if p, ok := overrides[name]; ok {
+		return p
+	}
+	upper := strings.ToUpper(path.Base(name))
+	switch {
+	case name == "TICKET.md":
+		return priorityTicket
+	case name == "project:guidelines":
+		return priorityGuidelines
+	case isFileList(name):
+		return priorityContext
+	case strings.HasPrefix(upper, "PLAN"):
+		return priorityPlan
+	case strings.HasPrefix(upper, "REVIEW"):
+		return priorityReview
+	}
+	return priorityDefault
+}
+
+// isFileList reports whether an input renders whole files under "### path"
+// headers, so that it can be truncated file by file.
+func isFileList(name string) bool {
+	return name == "project:context" || strings.HasPrefix(name, "glob:")
+}
+
+// TruncateToTokenBudget cuts inputs so that they and the system prompt stay
+// within maxTokens (rough estimate: 4 chars ≈ 1 token). Inputs are kept in
+// priority order (see InputPriority). Inputs of the priority level that
+// overflows share the remaining budget in proportion to their size; lower
+// levels are dropped. File list inputs such as project:context lose whole
+// files, least relevant first, instead of being cut mid-file.
+//
+// The report is nil when nothing had to be cut.
+func TruncateToTokenBudget(files map[string]string, priorities map[string]int, systemPrompt string, maxTokens int) (map[string]string, *run.TruncationReport) {
+	if maxTokens <= 0 {
+		return files, nil
+	}
+	// Reserve budget for system prompt
+	budget := maxTokens - len(systemPrompt)/4
+	if budget <= 0 {
+		return files, nil
+	}
+
+	total := 0
+	for _, content := range files {
+		total += len(content) / 4
+	}
+	if total <= budget {
+		return files, nil
+	}
+
+	report := &run.TruncationReport{Budget: budget}
+	for name, content := range files {
+		report.Inputs = append(report.Inputs, run.InputTruncation{
+			Name:     name,
+			Priority: InputPriority(name, priorities),
+			Tokens:   len(content) / 4,
+		})
+	}
+	sort.Slice(report.Inputs, func(i, j int) bool {
+		a, b := report.Inputs[i], report.Inputs[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.Name < b.Name
+	})
+
+	result := make(map[string]string, len(files))
+	remaining := budget
+	for start := 0; start < len(report.Inputs); {
+		// Inputs [start, end) share a priority level.
+		end := start
+		levelTokens := 0
+		for end < len(report.Inputs) && report.Inputs[end].Priority == report.Inputs[start].Priority {
+			levelTokens += report.Inputs[end].Tokens
+			end++
+		}
+
+		levelBudget := remaining
+		for i := start; i < end; i++ {
+			in := &report.Inputs[i]
+			allowance := in.Tokens
+			if levelTokens > levelBudget {
+				allowance = levelBudget * in.Tokens / levelTokens
+			}
+			content := truncateInput(files[in.Name], allowance, in)
+			if content == "" {
+				in.Dropped = true
+				continue
+			}
+			result[in.Name] = content
+			in.KeptTokens = len(content) / 4
+			remaining -= in.KeptTokens
+		}
+		if remaining < 0 {
+			remaining = 0
+		}
+		start = end
+	}
+	return result, report
+}
+
+// truncateInput cuts content to about allowance tokens, recording dropped
+// files in in. Returns "" when nothing is left.
+func truncateInput(content string, allowance int, in *run.InputTruncation) string {
+	if len(content)/4 <= allowance {
+		return content
+	}
+	if allowance <= 0 {
+		return ""
+	}
+	if !isFileList(in.Name) {
+		return truncateText(content, allowance)
+	}
+	if sections := fileSections(content); sections != nil {
+		return truncateSections(content, sections, allowance, in)
+	}
+	return truncateText(content, allowance)
+}
+
+// truncateText cuts content at a line boundary near allowance tokens.
+func truncateText(content string, allowance int) string {
+	maxChars := allowance*4 - len(truncationNote)
+	if maxChars <= 0 {
+		return ""
+	}
+	// Do not split a multi-byte character.
+	for maxChars > 0 && !utf8.RuneStart(content[maxChars]) {
+		maxChars--
+	}
+	if maxChars == 0 {
+		return ""
+	}
+	cut := content[:maxChars]
+	// Prefer a line boundary when one is reasonably close.
+	if i := strings.LastIndexByte(cut, '\n'); i > maxChars/2 {
+		cut = cut[:i]
+	}
+	return cut + truncationNote
+}
+
+// fileSection is one "### path" file of a file list input.
+type fileSection struct {
+	path       string
+	start, end int
+}
+
+// fileSections splits a file list input into its files, or returns nil if
+// content is not a file list.
+func fileSections(content string) []fileSection {
+	matches := fileSectionRe.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	sections := make([]fileSection, len(matches))
+	for i, m := range matches {
+		sections[i] = fileSection{path: content[m[2]:m[3]], start: m[0], end: len(content)}
+		if i > 0 {
+			sections[i-1].end = m[0]
+		}
+	}
+	return sections
+}
+
+// truncateSections keeps the preamble and, in order, every file that still
+// fits within allowance; the others are dropped whole.
+func truncateSections(content string, sections []fileSection, allowance int, in *run.InputTruncation) string {
+	var sb strings.Builder
+	sb.WriteString(content[:sections[0].start])
+	// Leave room for the note about omitted files.
+	used := sb.Len()/4 + 16
+	kept := 0
+	for _, s := range sections {
+		tokens := (s.end - s.start) / 4
+		if used+tokens > allowance {
+			in.DroppedFiles = append(in.DroppedFiles, s.path)
+			continue
+		}
+		sb.WriteString(content[s.start:s.end])
+		used += tokens
+		kept++
+	}
+	if kept == 0 {
+		return ""
+	}
+	fmt.Fprintf(&sb, "[... %d more files omitted due to token limit ...]\n", len(in.DroppedFiles))
+	return sb.String()
+}
+
+// truncatedInputs lists the names of the inputs a report cut or dropped.
+func truncatedInputs(r *run.TruncationReport) []string {
+	var names []string
+	for _, in := range r.Inputs {
+		if in.KeptTokens < in.Tokens {
+			names = append(names, in.Name)
+		}
+	}
+	return names
+}
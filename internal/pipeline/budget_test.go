@@ -0,0 +1,137 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateText(t *testing.T) {
+	lines := strings.Repeat("0123456789abcdef\n", 20) // 340 bytes
+	tests := []struct {
+		name      string
+		content   string
+		allowance int
+		want      string
+	}{
+		{"line boundary", lines, 25, strings.Repeat("0123456789abcdef\n", 2) + "0123456789abcdef" + truncationNote},
+		{"no line boundary", strings.Repeat("x", 400), 20, strings.Repeat("x", 80-len(truncationNote)) + truncationNote},
+		{"too small for the note", lines, 5, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateText(tt.content, tt.allowance); got != tt.want {
+				t.Errorf("truncateText(%d) =\n%q\nwant\n%q", tt.allowance, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateTextUTF8(t *testing.T) {
+	for _, content := range []string{
+		strings.Repeat("한국어 ", 100),    // 3-byte characters
+		strings.Repeat("🙂", 100),       // 4-byte characters
+		"a" + strings.Repeat("é", 200), // 2-byte characters at odd offsets
+	} {
+		for allowance := 1; allowance*4 < len(content); allowance++ {
+			got := truncateText(content, allowance)
+			if !utf8.ValidString(got) {
+				t.Fatalf("truncateText(%q..., %d) is not valid UTF-8: %q", content[:8], allowance, got)
+			}
+			if len(got) > allowance*4 {
+				t.Fatalf("truncateText(%q..., %d) is %d bytes, over the allowance", content[:8], allowance, len(got))
+			}
+		}
+	}
+}
+
+func TestInputPriority(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"TICKET.md", priorityTicket},
+		{"project:guidelines", priorityGuidelines},
+		{"PLAN.md", priorityPlan},
+		{"PLAN.candidate-2.md", priorityPlan},
+		{"REVIEW.md", priorityReview},
+		{"git:diff", priorityDefault},
+		{"project:context", priorityContext},
+		{"glob:**/*.go", priorityContext},
+		{"override.md", 95},
+	}
+	overrides := map[string]int{"override.md": 95}
+	for _, tt := range tests {
+		if got := InputPriority(tt.name, overrides); got != tt.want {
+			t.Errorf("InputPriority(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateToTokenBudget(t *testing.T) {
+	file := func(path string, tokens int) string {
+		return "### " + path + "\n\n```go\n" + strings.Repeat("x", tokens*4-len(path)-15) + "\n```\n"
+	}
+	ticket := strings.Repeat("t", 400) // 100 tokens
+	plan := strings.Repeat("p\n", 200) // 100 tokens
+	other := strings.Repeat("o", 2000) // 500 tokens
+	projectCtx := file("a.go", 100) + file("b.go", 100) + file("c.go", 100)
+
+	tests := []struct {
+		name       string
+		maxTokens  int
+		kept       map[string]bool // input → kept whole; missing inputs are dropped
+		dropped    []string        // files dropped from project:context
+		noTruncate bool
+	}{
+		{"within budget", 2000, nil, nil, true},
+		{"context loses files", 850, map[string]bool{"TICKET.md": true, "PLAN.md": true, "notes.md": true, "project:context": false}, []string{"b.go", "c.go"}, false},
+		{"lower levels dropped", 300, map[string]bool{"TICKET.md": true, "PLAN.md": true, "notes.md": false}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{"TICKET.md": ticket, "PLAN.md": plan, "notes.md": other, "project:context": projectCtx}
+			got, report := TruncateToTokenBudget(files, nil, "", tt.maxTokens)
+			if tt.noTruncate {
+				if report != nil || len(got) != len(files) {
+					t.Errorf("report = %+v, want no truncation", report)
+				}
+				return
+			}
+			if report == nil {
+				t.Fatal("no truncation report")
+			}
+			total := 0
+			for name, content := range got {
+				total += len(content) / 4
+				whole, ok := tt.kept[name]
+				switch {
+				case !ok:
+					t.Errorf("%s kept, want it dropped", name)
+				case whole && content != files[name]:
+					t.Errorf("%s cut, want it kept whole", name)
+				case !whole && content == files[name]:
+					t.Errorf("%s kept whole, want it cut", name)
+				}
+			}
+			for name := range tt.kept {
+				if _, ok := got[name]; !ok {
+					t.Errorf("%s dropped, want it kept", name)
+				}
+			}
+			if total > tt.maxTokens {
+				t.Errorf("kept %d tokens, over the budget of %d", total, tt.maxTokens)
+			}
+			if tt.dropped != nil {
+				for _, in := range report.Inputs {
+					if in.Name == "project:context" && strings.Join(in.DroppedFiles, ",") != strings.Join(tt.dropped, ",") {
+						t.Errorf("dropped files = %v, want %v", in.DroppedFiles, tt.dropped)
+					}
+				}
+				if !strings.Contains(got["project:context"], "### a.go") || strings.Contains(got["project:context"], "### b.go") {
+					t.Errorf("project:context not cut at a file boundary:\n%s", got["project:context"])
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+
+	"github.com/futureCreator/vcoding/internal/config"
+	"github.com/futureCreator/vcoding/internal/input"
+	"github.com/futureCreator/vcoding/internal/redact"
 )
 
 // Context manages file-based context between pipeline steps.
@@ -13,89 +17,63 @@ type Context struct {
 	RunDir     string
 	ProjectCtx string // pre-built project context markdown
 	GitDiff    string
+	Config     *config.Config
+	IssueRef   string // GitHub issue number for runs started with pick
+	Redactor   *redact.Redactor
 }
 
 // ResolveInput loads the content of each input spec.
-// Supports special inputs: "git:diff", "project:context"
-// and regular filenames resolved from RunDir.
-func (c *Context) ResolveInput(inputs []string) (map[string]string, error) {
+// Virtual inputs (e.g. "git:diff", "project:context", "file:main.go#L1-20")
+// are resolved by the providers registered in the input package; everything
+// else is a regular filename resolved from RunDir.
+func (c *Context) ResolveInput(ctx context.Context, inputs []string) (map[string]string, error) {
 	files := make(map[string]string)
+	env := c.inputEnv()
 
 	for _, inp := range inputs {
-		switch inp {
-		case "git:diff":
-			files["git:diff"] = c.GitDiff
-		case "project:context":
-			files["project:context"] = c.ProjectCtx
-		default:
-			content, err := c.readFile(inp)
+		if input.IsVirtual(inp) {
+			content, err := input.Resolve(ctx, env, inp)
 			if err != nil {
-				return nil, fmt.Errorf("reading input %q: %w", inp, err)
+				return nil, fmt.Errorf("resolving input %q: %w", inp, err)
 			}
 			files[inp] = content
+			continue
+		}
+		content, err := c.readFile(inp)
+		if err != nil {
+			return nil, fmt.Errorf("reading input %q: %w", inp, err)
 		}
+		files[inp] = content
 	}
 
 	return files, nil
 }
 
+func (c *Context) inputEnv() *input.Env {
+	return &input.Env{
+		RunDir:     c.RunDir,
+		ProjectCtx: c.ProjectCtx,
+		GitDiff:    c.GitDiff,
+		Config:     c.Config,
+		IssueRef:   c.IssueRef,
+		Redactor:   c.Redactor,
+	}
+}
+
 func (c *Context) readFile(name string) (string, error) {
 	// Try run directory first
 	runPath := filepath.Join(c.RunDir, name)
 	if data, err := os.ReadFile(runPath); err == nil {
 		return string(data), nil
 	}
-	// Try current working directory
+	// Try current working directory. Unlike run artifacts, these files were
+	// never checked for secrets.
 	if data, err := os.ReadFile(name); err == nil {
-		return string(data), nil
+		return c.Redactor.Redact(name, filepath.ToSlash(name), string(data))
 	}
 	return "", fmt.Errorf("file %q not found in run dir or working dir", name)
 }
 
-// TruncateToTokenBudget truncates the combined content of files to stay within
-// maxTokens (rough estimate: 4 chars ≈ 1 token).
-// Keys are processed in sorted order for deterministic results.
-func TruncateToTokenBudget(files map[string]string, systemPrompt string, maxTokens int) map[string]string {
-	if maxTokens <= 0 {
-		return files
-	}
-	// Reserve budget for system prompt
-	used := len(systemPrompt) / 4
-	budget := maxTokens - used
-	if budget <= 0 {
-		return files
-	}
-
-	// Sort keys for deterministic truncation order
-	keys := make([]string, 0, len(files))
-	for k := range files {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	result := make(map[string]string)
-	for _, name := range keys {
-		content := files[name]
-		contentTokens := len(content) / 4
-		if used+contentTokens <= budget {
-			result[name] = content
-			used += contentTokens
-		} else {
-			// Truncate this file to fit remaining budget
-			remaining := (budget - used) * 4
-			if remaining > 0 {
-				if remaining < len(content) {
-					content = content[:remaining]
-					content += "\n\n[... truncated due to token limit ...]"
-				}
-				result[name] = content
-			}
-			break
-		}
-	}
-	return result
-}
-
 // BuildTicketContent creates TICKET.md content from an input title + body.
 func BuildTicketContent(title, body string) string {
 	var sb strings.Builder
@@ -116,7 +94,14 @@ func FilterProjectContextByPlanFiles(planContent, projectCtx string) string {
 	}
 
 	targetFiles, _ := ExtractFilesFromPlan(planContent)
-	if len(targetFiles) == 0 {
+	return FilterProjectContext(projectCtx, targetFiles)
+}
+
+// FilterProjectContext keeps only the files in targetFiles from a project
+// context formatted by project.FormatContext.
+// If targetFiles is empty or nothing matches, returns the original context.
+func FilterProjectContext(projectCtx string, targetFiles []string) string {
+	if projectCtx == "" || len(targetFiles) == 0 {
 		return projectCtx
 	}
 
@@ -0,0 +1,220 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	vlog "github.com/futureCreator/vcoding/internal/log"
+	"github.com/futureCreator/vcoding/internal/project"
+	"github.com/futureCreator/vcoding/internal/run"
+	"github.com/futureCreator/vcoding/internal/types"
+)
+
+// applyContextFilter narrows inputFiles["project:context"] according to the
+// step's context_filter and saves the result as <Step>-context-filtered.md.
+// Returns nil stats if the step has no filter or no project context input.
+func (e *Engine) applyContextFilter(ctx context.Context, step types.Step, inputFiles map[string]string, pipelineCtx *Context) (*run.ContextFilterStats, error) {
+	f := step.ContextFilter
+	if f == nil {
+		return nil, nil
+	}
+	projectCtx, ok := inputFiles["project:context"]
+	if !ok || projectCtx == "" {
+		return nil, nil
+	}
+
+	source, ok := inputFiles[f.From]
+	if !ok {
+		resolved, err := pipelineCtx.ResolveInput(ctx, []string{f.From})
+		if err != nil {
+			return nil, fmt.Errorf("context_filter: %w", err)
+		}
+		source = resolved[f.From]
+	}
+
+	listed, _ := ExtractFilesFromSection(source, f.SectionName())
+	files := listed
+	if f.IncludeTests {
+		files = withTestFiles(files)
+	}
+
+	filtered := projectCtx
+	if len(files) > 0 {
+		filtered = FilterProjectContext(projectCtx, files)
+	}
+
+	stats := &run.ContextFilterStats{
+		From:     f.From,
+		Section:  f.SectionName(),
+		Files:    len(files),
+		Fallback: filtered == projectCtx,
+	}
+	if !stats.Fallback && (f.IncludeTests || f.IncludeCallers) {
+		related, err := e.addRelatedFiles(f, listed, filtered, pipelineCtx)
+		if err != nil {
+			return nil, err
+		}
+		filtered, stats.Related = related.context, related.files
+	}
+	stats.OriginalTokens = EstimateTokens(projectCtx)
+	stats.FilteredTokens = EstimateTokens(filtered)
+	stats.SavedTokens = stats.OriginalTokens - stats.FilteredTokens
+
+	if stats.Fallback {
+		vlog.Warn("context filter matched no files; using full project context",
+			"step", step.Name, "from", f.From, "section", stats.Section)
+	} else {
+		vlog.Debug("context filtering",
+			"step", step.Name,
+			"files", stats.Files,
+			"related", len(stats.Related),
+			"original_tokens", stats.OriginalTokens,
+			"filtered_tokens", stats.FilteredTokens)
+	}
+
+	// Save filtered context for debugging
+	if err := e.Run.WriteFile(step.Name+"-context-filtered.md", filtered); err != nil {
+		vlog.Warn("failed to save filtered context", "err", err)
+	}
+
+	inputFiles["project:context"] = filtered
+	return stats, nil
+}
+
+// defaultRelatedTokens is the default budget for files added by
+// include_tests and include_callers.
+const defaultRelatedTokens = 10000
+
+// relatedContext is a filtered context with related files appended.
+type relatedContext struct {
+	context string
+	files   []run.RelatedFile
+}
+
+// addRelatedFiles appends the tests (include_tests) and Go callers
+// (include_callers) of the listed files that are not in the filtered context
+// yet, read from the working tree, to the filtered context. Tests come
+// first, then callers with the most references; files that do not fit
+// within related_tokens are skipped. Secrets in added files are redacted.
+func (e *Engine) addRelatedFiles(f *types.ContextFilter, listed []string, filtered string, pipelineCtx *Context) (relatedContext, error) {
+	result := relatedContext{context: filtered}
+	present := map[string]bool{}
+	for _, s := range fileSections(filtered) {
+		present[s.path] = true
+	}
+
+	var candidates []run.RelatedFile
+	if f.IncludeTests {
+		for _, file := range listed {
+			for _, t := range TestFilesFor(file) {
+				candidates = append(candidates, run.RelatedFile{Path: t, Reason: "test of " + file})
+			}
+		}
+	}
+	if f.IncludeCallers {
+		callers, err := project.GoCallers(&e.Config.ProjectContext, listed)
+		if err != nil {
+			vlog.Warn("could not find callers of planned files", "err", err)
+		}
+		for _, c := range callers {
+			candidates = append(candidates, run.RelatedFile{Path: c.Path, Reason: c.Reason()})
+		}
+	}
+
+	budget := f.RelatedTokens
+	if budget == 0 {
+		budget = defaultRelatedTokens
+	}
+	maxSize, _ := project.ParseSize(e.Config.ProjectContext.MaxFileSize)
+
+	var list, sections strings.Builder
+	used := 0
+	for _, c := range candidates {
+		if present[c.Path] {
+			continue
+		}
+		info, err := os.Stat(filepath.FromSlash(c.Path))
+		if err != nil || info.IsDir() || info.Size() > maxSize {
+			continue
+		}
+		data, err := os.ReadFile(filepath.FromSlash(c.Path))
+		if err != nil {
+			continue
+		}
+		content, err := pipelineCtx.Redactor.Redact("project:context", c.Path, string(data))
+		if err != nil {
+			return result, err
+		}
+		section := fmt.Sprintf("### %s\n\n```\n%s\n```\n\n", c.Path, content)
+		if used+EstimateTokens(section) > budget {
+			continue
+		}
+		present[c.Path] = true
+		used += EstimateTokens(section)
+		fmt.Fprintf(&list, "- %s: %s\n", c.Path, c.Reason)
+		sections.WriteString(section)
+		result.files = append(result.files, c)
+	}
+	if len(result.files) == 0 {
+		return result, nil
+	}
+	result.context = strings.TrimRight(filtered, "\n") + "\n\n## Related Files\n\n" +
+		"Tests and callers of the planned files, added automatically:\n\n" + list.String() + "\n" + sections.String()
+	return result, nil
+}
+
+// withTestFiles appends the conventional test file names of each file.
+// Names that do not exist are harmless: they simply match nothing.
+func withTestFiles(files []string) []string {
+	seen := make(map[string]bool, len(files))
+	result := make([]string, 0, len(files)*2)
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			result = append(result, f)
+		}
+	}
+	for _, f := range files {
+		add(f)
+		for _, t := range TestFilesFor(f) {
+			add(t)
+		}
+	}
+	return result
+}
+
+// TestFilesFor returns the conventional test file paths for a source file,
+// e.g. foo.go → foo_test.go, foo.ts → foo.test.ts, foo.py → test_foo.py.
+func TestFilesFor(file string) []string {
+	dir, base := path.Split(file)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	var names []string
+	switch ext {
+	case ".go":
+		if !strings.HasSuffix(stem, "_test") {
+			names = []string{stem + "_test.go"}
+		}
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs":
+		if !strings.HasSuffix(stem, ".test") && !strings.HasSuffix(stem, ".spec") {
+			names = []string{stem + ".test" + ext, stem + ".spec" + ext}
+		}
+	case ".py":
+		if !strings.HasPrefix(stem, "test_") && !strings.HasSuffix(stem, "_test") {
+			names = []string{"test_" + stem + ".py", stem + "_test.py"}
+		}
+	case ".rs":
+		names = []string{"tests/" + stem + ".rs"}
+	}
+
+	result := make([]string, 0, len(names))
+	for _, n := range names {
+		result = append(result, dir+n)
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/futureCreator/vcoding/internal/config"
+	"github.com/futureCreator/vcoding/internal/redact"
+)
+
+func TestResolveInputRedactsWorkingDirFiles(t *testing.T) {
+	redactor, err := redact.New(config.RedactionConfig{Mode: "redact"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := "ghp_" + strings.Repeat("a1B2", 9)
+	path := filepath.Join(t.TempDir(), "app.env")
+	if err := os.WriteFile(path, []byte("GITHUB_TOKEN="+secret+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Context{RunDir: t.TempDir(), Redactor: redactor}
+	files, err := c.ResolveInput(context.Background(), []string{path})
+	if err != nil {
+		t.Fatalf("ResolveInput: %v", err)
+	}
+	if strings.Contains(files[path], secret) {
+		t.Errorf("secret not redacted: %q", files[path])
+	}
+	if got := redactor.Findings(); len(got) == 0 || got[0].Input != path {
+		t.Errorf("findings = %+v, want one for input %q", got, path)
+	}
+}
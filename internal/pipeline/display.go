@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -8,18 +9,58 @@ import (
 	"strings"
 	"time"
 	"unicode/utf8"
+
+	"github.com/futureCreator/vcoding/internal/run"
 )
 
-// Display handles terminal progress output for the pipeline.
-type Display struct {
+// Display receives pipeline progress events.
+type Display interface {
+	// Header is called once before the first step.
+	Header()
+	StepStart(name, model string)
+	// StepDelta receives streamed model output for the running step.
+	StepDelta(name, delta string)
+	StepDone(report StepReport)
+	StepSkipped(name, model, reason string)
+	StepFailed(name, model string, err error)
+	// Summary is called once after all steps completed.
+	Summary(totalCost float64, totalDuration time.Duration)
+	// Failed is called once when the run failed, with the cost spent so far.
+	Failed(totalCost float64, err error)
+	// Cancelled is called once when the run was cancelled during step, with
+	// the cost spent so far.
+	Cancelled(totalCost float64, step string)
+}
+
+// StepReport describes a completed step.
+type StepReport struct {
+	Name            string
+	Model           string
+	Detail          string // short description shown in the progress line
+	Artifact        string // path of the written output file, empty if none
+	ArtifactContent string
+	Cost            float64
+	TokensIn        int
+	TokensOut       int
+	Attempts        int // executor calls, including corrective re-prompts
+	Duration        time.Duration
+	ContextFilter   *run.ContextFilterStats
+	Partial         string // file holding output streamed before cancellation
+	Candidates      []run.CandidateResult
+	Judge           *run.JudgeResult
+	Truncation      *run.TruncationReport
+}
+
+// TextDisplay handles terminal progress output for the pipeline.
+type TextDisplay struct {
 	w       io.Writer
 	title   string
 	verbose bool
 }
 
-// NewDisplay creates a display that writes to stdout.
-func NewDisplay(title string, verbose bool) *Display {
-	return &Display{w: os.Stdout, title: title, verbose: verbose}
+// NewDisplay creates a text display that writes to stdout.
+func NewDisplay(title string, verbose bool) *TextDisplay {
+	return &TextDisplay{w: os.Stdout, title: title, verbose: verbose}
 }
 
 // modelColumnWidth is the fixed display width reserved for the model/executor column.
@@ -45,7 +86,7 @@ func truncateModel(model string) string {
 }
 
 // Header prints the pipeline header.
-func (d *Display) Header() {
+func (d *TextDisplay) Header() {
 	fmt.Fprintf(d.w, "\n🐙 vCoding — %s\n", d.title)
 	fmt.Fprintln(d.w, strings.Repeat("─", 76))
 }
@@ -53,7 +94,7 @@ func (d *Display) Header() {
 // StepStart prints a step-in-progress line.
 // In non-verbose mode, the line is printed without newline so it can be overwritten.
 // In verbose mode, a plain line is printed (executor output follows on subsequent lines).
-func (d *Display) StepStart(name, model string) {
+func (d *TextDisplay) StepStart(name, model string) {
 	model = truncateModel(model)
 	if d.verbose {
 		fmt.Fprintf(d.w, "⏳ %-12s %-30s running...\n", name, model)
@@ -63,40 +104,200 @@ func (d *Display) StepStart(name, model string) {
 	fmt.Fprintf(d.w, "⏳ %-12s %-30s running...", name, model)
 }
 
+// StepDelta prints streamed executor output in verbose mode.
+func (d *TextDisplay) StepDelta(name, delta string) {
+	if d.verbose {
+		fmt.Fprint(d.w, delta)
+	}
+}
+
 // StepDone prints a completed step line, overwriting the running line in non-verbose mode.
-func (d *Display) StepDone(name, model, detail string, cost float64, duration time.Duration, artifactContent string) {
-	model = truncateModel(model)
+func (d *TextDisplay) StepDone(r StepReport) {
+	model := truncateModel(r.Model)
 	costStr := "—"
-	if cost > 0 {
-		costStr = fmt.Sprintf("$%.4f", cost)
+	if r.Cost > 0 {
+		costStr = fmt.Sprintf("$%.4f", r.Cost)
 	}
 	prefix := "\r"
 	if d.verbose {
-		prefix = ""
+		// Streamed output does not necessarily end with a newline.
+		prefix = "\n"
 	}
 	fmt.Fprintf(d.w, "%s✅ %-12s %-30s %-28s %-10s %.1fs\n",
-		prefix, name, model, detail, costStr, duration.Seconds())
+		prefix, r.Name, model, r.Detail, costStr, r.Duration.Seconds())
 }
 
-// StepFailed prints a failed step line, overwriting the running line in non-verbose mode.
-func (d *Display) StepFailed(name, model string, err error) {
+// StepSkipped prints a skipped step line, overwriting the running line in non-verbose mode.
+func (d *TextDisplay) StepSkipped(name, model, reason string) {
 	model = truncateModel(model)
 	prefix := "\r"
 	if d.verbose {
 		prefix = ""
 	}
+	fmt.Fprintf(d.w, "%s⏭️  %-12s %-30s skipped: %s\n", prefix, name, model, reason)
+}
+
+// StepFailed prints a failed step line, overwriting the running line in non-verbose mode.
+func (d *TextDisplay) StepFailed(name, model string, err error) {
+	model = truncateModel(model)
+	prefix := "\r"
+	if d.verbose {
+		prefix = "\n"
+	}
 	fmt.Fprintf(d.w, "%s❌ %-12s %-30s %s\n", prefix, name, model, err.Error())
 }
 
 // Summary prints the final run summary.
-func (d *Display) Summary(totalCost float64, totalDuration time.Duration) {
+func (d *TextDisplay) Summary(totalCost float64, totalDuration time.Duration) {
 	fmt.Fprintln(d.w, strings.Repeat("─", 76))
 	fmt.Fprintf(d.w, "✅ Done  $%.4f  %.0fs\n", totalCost, totalDuration.Seconds())
 	fmt.Fprintln(d.w)
 }
 
 // Failed prints a failure summary.
-func (d *Display) Failed(err error) {
+func (d *TextDisplay) Failed(totalCost float64, err error) {
 	fmt.Fprintln(d.w, strings.Repeat("─", 76))
 	fmt.Fprintf(d.w, "❌ Failed: %s\n\n", err.Error())
 }
+
+// Cancelled prints a cancellation summary.
+func (d *TextDisplay) Cancelled(totalCost float64, step string) {
+	fmt.Fprintln(d.w, strings.Repeat("─", 76))
+	fmt.Fprintf(d.w, "⛔ Cancelled during %s\n\n", step)
+}
+
+// Event is a single line of the JSON event stream.
+type Event struct {
+	Type       string    `json:"type"` // run_started | step_started | token_delta | step_completed | step_skipped | step_failed | run_completed
+	Time       time.Time `json:"time"`
+	RunID      string    `json:"run_id"`
+	RunDir     string    `json:"run_dir,omitempty"`
+	Pipeline   string    `json:"pipeline,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	Step       string    `json:"step,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	Delta      string    `json:"delta,omitempty"`
+	Artifact   string    `json:"artifact,omitempty"`
+	Cost       float64   `json:"cost,omitempty"`
+	TokensIn   int       `json:"tokens_in,omitempty"`
+	TokensOut  int       `json:"tokens_out,omitempty"`
+	Attempts   int       `json:"attempts,omitempty"`
+	DurationMS int64     `json:"duration_ms,omitempty"`
+	Status     string    `json:"status,omitempty"` // run_completed: "completed" | "failed" | "cancelled"
+	Error      string    `json:"error,omitempty"`
+}
+
+// JSONDisplay writes newline-delimited JSON events.
+type JSONDisplay struct {
+	enc       *json.Encoder
+	runID     string
+	runDir    string
+	pipeline  string
+	title     string
+	start     time.Time
+	tokensIn  int
+	tokensOut int
+}
+
+// NewJSONDisplay creates a JSON event display that writes to stdout.
+func NewJSONDisplay(title, runID, runDir, pipelineName string) *JSONDisplay {
+	return &JSONDisplay{
+		enc:      json.NewEncoder(os.Stdout),
+		runID:    runID,
+		runDir:   runDir,
+		pipeline: pipelineName,
+		title:    title,
+		start:    time.Now(),
+	}
+}
+
+func (d *JSONDisplay) emit(ev Event) {
+	ev.Time = time.Now()
+	ev.RunID = d.runID
+	// Encoding errors (e.g. a closed pipe) cannot be reported anywhere useful.
+	_ = d.enc.Encode(ev)
+}
+
+// Header emits run_started.
+func (d *JSONDisplay) Header() {
+	d.start = time.Now()
+	d.emit(Event{Type: "run_started", RunDir: d.runDir, Pipeline: d.pipeline, Title: d.title})
+}
+
+// StepStart emits step_started.
+func (d *JSONDisplay) StepStart(name, model string) {
+	d.emit(Event{Type: "step_started", Step: name, Model: model})
+}
+
+// StepDelta emits token_delta.
+func (d *JSONDisplay) StepDelta(name, delta string) {
+	d.emit(Event{Type: "token_delta", Step: name, Delta: delta})
+}
+
+// StepDone emits step_completed.
+func (d *JSONDisplay) StepDone(r StepReport) {
+	d.tokensIn += r.TokensIn
+	d.tokensOut += r.TokensOut
+	d.emit(Event{
+		Type:       "step_completed",
+		Step:       r.Name,
+		Model:      r.Model,
+		Artifact:   r.Artifact,
+		Cost:       r.Cost,
+		TokensIn:   r.TokensIn,
+		TokensOut:  r.TokensOut,
+		Attempts:   r.Attempts,
+		DurationMS: r.Duration.Milliseconds(),
+	})
+}
+
+// StepSkipped emits step_skipped.
+func (d *JSONDisplay) StepSkipped(name, model, reason string) {
+	d.emit(Event{Type: "step_skipped", Step: name, Model: model, Error: reason})
+}
+
+// StepFailed emits step_failed.
+func (d *JSONDisplay) StepFailed(name, model string, err error) {
+	d.emit(Event{Type: "step_failed", Step: name, Model: model, Error: err.Error()})
+}
+
+// Summary emits run_completed with status "completed".
+func (d *JSONDisplay) Summary(totalCost float64, totalDuration time.Duration) {
+	d.emit(Event{
+		Type:       "run_completed",
+		RunDir:     d.runDir,
+		Status:     "completed",
+		Cost:       totalCost,
+		TokensIn:   d.tokensIn,
+		TokensOut:  d.tokensOut,
+		DurationMS: totalDuration.Milliseconds(),
+	})
+}
+
+// Failed emits run_completed with status "failed".
+func (d *JSONDisplay) Failed(totalCost float64, err error) {
+	d.emit(Event{
+		Type:       "run_completed",
+		RunDir:     d.runDir,
+		Status:     "failed",
+		Error:      err.Error(),
+		Cost:       totalCost,
+		TokensIn:   d.tokensIn,
+		TokensOut:  d.tokensOut,
+		DurationMS: time.Since(d.start).Milliseconds(),
+	})
+}
+
+// Cancelled emits run_completed with status "cancelled".
+func (d *JSONDisplay) Cancelled(totalCost float64, step string) {
+	d.emit(Event{
+		Type:       "run_completed",
+		RunDir:     d.runDir,
+		Step:       step,
+		Status:     "cancelled",
+		Cost:       totalCost,
+		TokensIn:   d.tokensIn,
+		TokensOut:  d.tokensOut,
+		DurationMS: time.Since(d.start).Milliseconds(),
+	})
+}
@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/futureCreator/vcoding/internal/cost"
+	"github.com/futureCreator/vcoding/internal/executor"
+	vlog "github.com/futureCreator/vcoding/internal/log"
+)
+
+// dryRun writes the system and user messages of req to <Step>.system.md and
+// <Step>.user.md (<Step>-<n>.* for later requests of the same step) and
+// returns a placeholder result with estimated input tokens and cost.
+func (e *Engine) dryRun(req *executor.Request, report *StepReport) (*executor.Result, error) {
+	step := req.Step
+	report.Attempts++
+
+	if e.dryRuns == nil {
+		e.dryRuns = map[string]int{}
+	}
+	e.dryRuns[step.Name]++
+	base := step.Name
+	if n := e.dryRuns[step.Name]; n > 1 {
+		base = fmt.Sprintf("%s-%d", step.Name, n)
+	}
+	user := executor.UserContent(req)
+	if err := e.Run.WriteFile(base+".system.md", req.SystemPrompt); err != nil {
+		return nil, fmt.Errorf("writing dry-run request: %w", err)
+	}
+	if err := e.Run.WriteFile(base+".user.md", user); err != nil {
+		return nil, fmt.Errorf("writing dry-run request: %w", err)
+	}
+
+	tokens := EstimateTokens(req.SystemPrompt) + EstimateTokens(user)
+	estimate, ok := cost.EstimateInput(step.Model, tokens)
+	if !ok && step.Executor == "api" {
+		vlog.Debug("no built-in pricing for model; cost not estimated", "step", step.Name, "model", step.Model)
+	}
+	report.TokensIn += tokens
+	report.Cost += estimate
+
+	return &executor.Result{
+		Output:   placeholderOutput(step.Name, step.Output),
+		Cost:     estimate,
+		TokensIn: tokens,
+	}, nil
+}
+
+// placeholderOutput stands in for a step's output in dry-run mode so that
+// later steps can still resolve it as an input.
+func placeholderOutput(stepName, output string) string {
+	if output == "" {
+		output = "output"
+	}
+	return fmt.Sprintf("[dry run: %s of step %q would appear here]\n", output, stepName)
+}
@@ -0,0 +1,47 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/futureCreator/vcoding/internal/executor"
+	"github.com/futureCreator/vcoding/internal/run"
+	"github.com/futureCreator/vcoding/internal/types"
+)
+
+func TestDryRunCandidates(t *testing.T) {
+	dir := t.TempDir()
+	e := &Engine{Run: &run.Run{ID: "test", Dir: dir}, DryRun: true}
+	step := types.Step{
+		Name:       "Plan",
+		Executor:   "api",
+		Model:      "test/model",
+		Output:     "PLAN.md",
+		Candidates: &types.Candidates{Count: 3},
+	}
+	req := &executor.Request{
+		Step:         step,
+		SystemPrompt: "system",
+		InputFiles:   map[string]string{"TICKET.md": "ticket"},
+	}
+
+	var report StepReport
+	if err := e.runCandidates(context.Background(), nil, req, &report); err != nil {
+		t.Fatalf("runCandidates: %v", err)
+	}
+	if report.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", report.Attempts)
+	}
+	for _, base := range []string{"Plan", "Plan-2", "Plan-3"} {
+		for _, name := range []string{base + ".system.md", base + ".user.md"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("request of candidate not rendered: %v", err)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Plan-4.system.md")); err == nil {
+		t.Error("Plan-4.system.md rendered for 3 candidates")
+	}
+}
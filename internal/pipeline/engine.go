@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,19 +11,30 @@ import (
 
 	"github.com/futureCreator/vcoding/internal/config"
 	"github.com/futureCreator/vcoding/internal/executor"
+	"github.com/futureCreator/vcoding/internal/hooks"
 	vlog "github.com/futureCreator/vcoding/internal/log"
+	"github.com/futureCreator/vcoding/internal/prompt"
 	"github.com/futureCreator/vcoding/internal/run"
 	"github.com/futureCreator/vcoding/internal/types"
 )
 
 // Engine orchestrates pipeline step execution.
 type Engine struct {
-	Config    *config.Config
-	Pipeline  *Pipeline
-	Executors map[string]executor.Executor
-	Run       *run.Run
-	Display   *Display
-	Verbose   bool
+	Config     *config.Config
+	Pipeline   *Pipeline
+	Executors  map[string]executor.Executor
+	Prompts    *prompt.Set
+	PromptData prompt.Data // step fields are filled in per step
+	Run        *run.Run
+	Display    Display
+	Verbose    bool
+	// DryRun renders each step's request into the run directory instead of
+	// calling its executor, and substitutes placeholders for step outputs.
+	DryRun bool
+
+	candidates map[string][]string // artifact → candidate files written so far
+	dryRuns    map[string]int      // step → requests rendered in a dry run so far
+	partial    strings.Builder     // output streamed by the current executor call
 }
 
 // stepDisplayModel returns a human-readable label for the step's executor/model,
@@ -42,64 +54,216 @@ func (e *Engine) stepDisplayModel(step types.Step) string {
 	}
 }
 
+// Validate renders the prompt template of every step so that unknown templates
+// and references to undefined variables are reported before any step runs.
+// Step hook options, expect rules, context filters and candidate/judge pairs
+// are checked as well.
+func (e *Engine) Validate() error {
+	unjudged := map[string]string{} // candidate artifact → producing step
+	for _, step := range e.Pipeline.Steps {
+		if _, err := e.renderPrompt(step); err != nil {
+			return fmt.Errorf("step %q: %w", step.Name, err)
+		}
+		if err := step.Hooks.Validate(); err != nil {
+			return fmt.Errorf("step %q: %w", step.Name, err)
+		}
+		if step.Expect != nil {
+			if err := step.Expect.Validate(); err != nil {
+				return fmt.Errorf("step %q: %w", step.Name, err)
+			}
+		}
+		if step.ContextFilter != nil {
+			if err := step.ContextFilter.Validate(); err != nil {
+				return fmt.Errorf("step %q: %w", step.Name, err)
+			}
+		}
+		if step.Judge != nil {
+			if err := step.Judge.Validate(); err != nil {
+				return fmt.Errorf("step %q: %w", step.Name, err)
+			}
+			if _, ok := unjudged[step.Judge.From]; !ok {
+				return fmt.Errorf("step %q: judge.from %q is not the output of an earlier step with candidates", step.Name, step.Judge.From)
+			}
+			delete(unjudged, step.Judge.From)
+		}
+		if step.Candidates != nil {
+			if err := step.Candidates.Validate(); err != nil {
+				return fmt.Errorf("step %q: %w", step.Name, err)
+			}
+			if step.Output == "" {
+				return fmt.Errorf("step %q: candidates require an output", step.Name)
+			}
+			unjudged[step.Output] = step.Name
+		}
+	}
+	for _, step := range e.Pipeline.Steps {
+		if name, ok := unjudged[step.Output]; ok && name == step.Name {
+			return fmt.Errorf("step %q: candidates of %s are never judged (add a later step with judge.from: %s)", step.Name, step.Output, step.Output)
+		}
+	}
+	return nil
+}
+
+// renderPrompt renders the step's system prompt. Steps without a
+// prompt template yield an empty prompt.
+func (e *Engine) renderPrompt(step types.Step) (string, error) {
+	if step.PromptTemplate == "" {
+		return "", nil
+	}
+	if e.Prompts == nil {
+		return "", fmt.Errorf("prompt template %q not found", step.PromptTemplate)
+	}
+	data := e.PromptData.ForStep(prompt.StepData{
+		Name:   step.Name,
+		Model:  e.resolveModel(step.Model),
+		Output: step.Output,
+	}, step.Vars)
+	return e.Prompts.Render(step.PromptTemplate, data)
+}
+
 // Execute runs all steps in sequence.
 func (e *Engine) Execute(ctx context.Context, pipelineCtx *Context) error {
 	startTime := time.Now()
 
 	for _, step := range e.Pipeline.Steps {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+		if ctx.Err() != nil {
+			return e.cancel(ctx, step.Name, e.runHookEnv(), nil)
 		}
 
 		displayModel := e.stepDisplayModel(step)
 		e.Display.StepStart(step.Name, displayModel)
 		stepStart := time.Now()
+		hookEnv := e.stepHookEnv(step, displayModel)
 
 		var stepErr error
-		var detail string
-		var cost float64
-		var artifactContent string
+		var report StepReport
+		var skipReason string
 
 		if step.Executor == "" {
 			stepErr = fmt.Errorf("step %q has no executor", step.Name)
-		} else {
-			detail, artifactContent, cost, stepErr = e.runExecutorStep(ctx, step, pipelineCtx)
+		} else if skipReason, stepErr = e.runPreStepHooks(ctx, step, hookEnv); stepErr == nil && skipReason == "" {
+			report, stepErr = e.runExecutorStep(ctx, step, pipelineCtx)
+			if stepErr == nil {
+				stepErr = e.runHooks(ctx, hooks.PostStep, hookEnv, e.Config.Hooks.PostStep, step.Hooks.PostStep)
+			}
 		}
 
 		duration := time.Since(stepStart)
 
+		if skipReason != "" {
+			sr := run.StepResult{
+				Name:       step.Name,
+				Status:     "skipped",
+				DurationMS: duration.Milliseconds(),
+				Error:      skipReason,
+			}
+			if err := e.Run.AddStepResult(sr); err != nil {
+				vlog.Warn("failed to save step result", "step", step.Name, "err", err)
+			}
+			e.Display.StepSkipped(step.Name, displayModel, skipReason)
+			continue
+		}
+
+		if stepErr != nil && ctx.Err() != nil {
+			sr := run.StepResult{
+				Name:       step.Name,
+				Status:     "cancelled",
+				Cost:       report.Cost,
+				TokensIn:   report.TokensIn,
+				TokensOut:  report.TokensOut,
+				Attempts:   report.Attempts,
+				DurationMS: duration.Milliseconds(),
+				Partial:    report.Partial,
+				Candidates: report.Candidates,
+			}
+			if err := e.Run.AddStepResult(sr); err != nil {
+				vlog.Warn("failed to save step result", "step", step.Name, "err", err)
+			}
+			e.Display.StepFailed(step.Name, displayModel, errCancelled)
+			return e.cancel(ctx, step.Name, hookEnv, step.Hooks.OnFailure)
+		}
+
 		if stepErr != nil {
+			sr := run.StepResult{
+				Name:       step.Name,
+				Status:     "failed",
+				Cost:       report.Cost,
+				TokensIn:   report.TokensIn,
+				TokensOut:  report.TokensOut,
+				Attempts:   report.Attempts,
+				DurationMS: duration.Milliseconds(),
+				Error:      stepErr.Error(),
+				Candidates: report.Candidates,
+			}
+			if err := e.Run.AddStepResult(sr); err != nil {
+				vlog.Warn("failed to save step result", "step", step.Name, "err", err)
+			}
 			e.Display.StepFailed(step.Name, displayModel, stepErr)
+			hookEnv["VCODING_STATUS"] = "failed"
+			hookEnv["VCODING_ERROR"] = stepErr.Error()
+			e.runHooksLogged(ctx, hooks.OnFailure, hookEnv, step.Hooks.OnFailure)
 			if err := e.Run.Fail(stepErr.Error()); err != nil {
 				vlog.Error("failed to update run meta", "err", err)
 			}
-			e.Display.Failed(stepErr)
+			e.runHooksLogged(ctx, hooks.OnFailure, hookEnv, e.Config.Hooks.OnFailure)
+			e.Display.Failed(e.Run.Meta.TotalCost, stepErr)
 			return fmt.Errorf("step %q failed: %w", step.Name, stepErr)
 		}
 
 		sr := run.StepResult{
 			Name:       step.Name,
 			Status:     "completed",
-			Cost:       cost,
+			Cost:       report.Cost,
+			TokensIn:   report.TokensIn,
+			TokensOut:  report.TokensOut,
+			Attempts:   report.Attempts,
 			DurationMS: duration.Milliseconds(),
+
+			ContextFilter: report.ContextFilter,
+			Candidates:    report.Candidates,
+			Judge:         report.Judge,
+			Truncation:    report.Truncation,
 		}
 		if err := e.Run.AddStepResult(sr); err != nil {
 			vlog.Warn("failed to save step result", "step", step.Name, "err", err)
 		}
 
-		e.Display.StepDone(step.Name, displayModel, detail, cost, duration, artifactContent)
+		hookEnv["VCODING_STATUS"] = "completed"
+		e.runHooksLogged(ctx, hooks.OnSuccess, hookEnv, step.Hooks.OnSuccess)
+
+		report.Name = step.Name
+		report.Model = displayModel
+		report.Duration = duration
+		e.Display.StepDone(report)
 	}
 
 	if err := e.Run.Complete(); err != nil {
 		vlog.Warn("failed to mark run complete", "err", err)
 	}
+	runEnv := e.runHookEnv()
+	runEnv["VCODING_STATUS"] = "completed"
+	e.runHooksLogged(ctx, hooks.OnSuccess, runEnv, e.Config.Hooks.OnSuccess)
 
 	e.Display.Summary(e.Run.Meta.TotalCost, time.Since(startTime))
 	return nil
 }
 
+// errCancelled is reported for the step interrupted by cancellation.
+var errCancelled = errors.New("cancelled")
+
+// cancel marks the run cancelled during step and runs the step's and the
+// configured on_failure hooks with VCODING_STATUS=cancelled. Hooks run
+// detached from the cancelled context, bounded by their own timeout.
+func (e *Engine) cancel(ctx context.Context, step string, hookEnv map[string]string, stepHooks []string) error {
+	if err := e.Run.Cancel(step); err != nil {
+		vlog.Error("failed to update run meta", "err", err)
+	}
+	hookEnv["VCODING_STATUS"] = "cancelled"
+	e.runHooksLogged(context.WithoutCancel(ctx), hooks.OnFailure, hookEnv, stepHooks, e.Config.Hooks.OnFailure)
+	e.Display.Cancelled(e.Run.Meta.TotalCost, step)
+	return fmt.Errorf("run cancelled during step %q: %w", step, ctx.Err())
+}
+
 // resolveModel replaces role placeholders ($planner, $reviewer, $editor)
 // with the corresponding model ID from config. If the model string is not a
 // placeholder, it is returned unchanged.
@@ -115,95 +279,160 @@ func (e *Engine) resolveModel(model string) string {
 	return model
 }
 
-func (e *Engine) runExecutorStep(ctx context.Context, step types.Step, pipelineCtx *Context) (detail, artifactContent string, cost float64, err error) {
+// runExecutorStep resolves the step's inputs, runs its executor and writes its
+// output artifact. The returned report has no name, model or duration set.
+func (e *Engine) runExecutorStep(ctx context.Context, step types.Step, pipelineCtx *Context) (StepReport, error) {
 	step.Model = e.resolveModel(step.Model)
 
 	exec, ok := e.Executors[step.Executor]
 	if !ok {
-		return "", "", 0, fmt.Errorf("unknown executor %q", step.Executor)
+		return StepReport{}, fmt.Errorf("unknown executor %q", step.Executor)
 	}
 
-	inputFiles, err := pipelineCtx.ResolveInput(step.Input)
+	inputFiles, err := pipelineCtx.ResolveInput(ctx, step.Input)
 	if err != nil {
-		return "", "", 0, err
-	}
-
-	// For Revise step, filter project context to only include files from PLAN.md
-	if strings.EqualFold(step.Name, "Revise") {
-		if planContent, ok := inputFiles["PLAN.md"]; ok {
-			if projectCtx, ok := inputFiles["project:context"]; ok && projectCtx != "" {
-				filteredCtx := FilterProjectContextByPlanFiles(planContent, projectCtx)
-
-				// Debug: Log token counts and save filtered context (only when verbose)
-				if e.Verbose {
-					originalTokens := EstimateTokens(projectCtx)
-					filteredTokens := EstimateTokens(filteredCtx)
-					targetFiles, _ := ExtractFilesFromPlan(planContent)
-
-					vlog.Debug("Revise context filtering",
-						"files_in_plan", len(targetFiles),
-						"original_tokens", originalTokens,
-						"filtered_tokens", filteredTokens,
-						"reduction_percent", fmt.Sprintf("%.1f%%", float64(originalTokens-filteredTokens)/float64(originalTokens)*100))
-				}
-
-				// Save filtered context for debugging
-				if writeErr := e.Run.WriteFile("Revise-context-filtered.md", filteredCtx); writeErr != nil {
-					vlog.Warn("failed to save filtered context", "err", writeErr)
-				}
+		return StepReport{}, err
+	}
 
-				inputFiles["project:context"] = filteredCtx
+	if step.Judge != nil {
+		for _, f := range e.candidates[step.Judge.From] {
+			content, err := e.Run.ReadFile(f)
+			if err != nil {
+				return StepReport{}, fmt.Errorf("reading candidate %s: %w", f, err)
 			}
+			inputFiles[f] = content
 		}
 	}
 
+	filterStats, err := e.applyContextFilter(ctx, step, inputFiles, pipelineCtx)
+	if err != nil {
+		return StepReport{}, err
+	}
+
+	systemPrompt, err := e.renderPrompt(step)
+	if err != nil {
+		return StepReport{}, err
+	}
+
 	// Apply token budget truncation for API steps.
+	var truncation *run.TruncationReport
 	if step.Executor == "api" && e.Config.MaxContextTokens > 0 {
-		sp, _ := resolvePromptForBudget(e, step)
-		inputFiles = TruncateToTokenBudget(inputFiles, sp, e.Config.MaxContextTokens)
+		inputFiles, truncation = TruncateToTokenBudget(inputFiles, step.Priorities, systemPrompt, e.Config.MaxContextTokens)
+		if truncation != nil {
+			vlog.Warn("inputs truncated to fit max_context_tokens", "step", step.Name, "budget", truncation.Budget, "truncated", truncatedInputs(truncation))
+		}
 	}
 
 	req := &executor.Request{
-		Step:       step,
-		RunDir:     e.Run.Dir,
-		InputFiles: inputFiles,
+		Step:         step,
+		RunDir:       e.Run.Dir,
+		SystemPrompt: systemPrompt,
+		InputFiles:   inputFiles,
+		OnDelta: func(delta string) {
+			e.partial.WriteString(delta)
+			e.Display.StepDelta(step.Name, delta)
+		},
+	}
+
+	report := StepReport{ContextFilter: filterStats, Truncation: truncation}
+	if step.Candidates != nil {
+		err := e.runCandidates(ctx, exec, req, &report)
+		return report, err
 	}
 
-	result, err := exec.Execute(ctx, req)
+	result, err := e.executeChecked(ctx, exec, req, &report)
 	if err != nil {
-		return "", "", 0, err
+		return report, err
 	}
 
 	// Save output file to run directory
 	if step.Output != "" && result.Output != "" {
-		if writeErr := e.Run.WriteFile(step.Output, result.Output); writeErr != nil {
+		if writeErr := e.Run.WriteArtifact(step.Output, step.Name, result.Output); writeErr != nil {
 			vlog.Warn("failed to write output file", "file", step.Output, "err", writeErr)
+		} else {
+			report.Artifact = e.Run.FilePath(step.Output)
 		}
-		detail = step.Output
-		artifactContent = result.Output
+		report.Detail = step.Output
+		report.ArtifactContent = result.Output
 	} else if step.Output == "" {
-		detail = fmt.Sprintf("%.0fs", result.Duration.Seconds())
+		report.Detail = fmt.Sprintf("%.0fs", result.Duration.Seconds())
+	}
+	if e.DryRun {
+		report.Detail = fmt.Sprintf("~%d tokens in", report.TokensIn)
 	}
 
-	return detail, artifactContent, result.Cost, nil
+	if step.Judge != nil {
+		if err := e.promoteWinner(step, result.Output, &report); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
 }
 
-// resolvePromptForBudget returns the system prompt text for token budget accounting.
-func resolvePromptForBudget(e *Engine, step types.Step) (string, bool) {
-	if step.PromptTemplate == "" {
-		return "", false
+// executeChecked runs req and re-prompts the model while its output violates
+// the step's expect rules. Usage of every attempt is added to report.
+func (e *Engine) executeChecked(ctx context.Context, exec executor.Executor, req *executor.Request, report *StepReport) (*executor.Result, error) {
+	step := req.Step
+	if e.DryRun {
+		return e.dryRun(req, report)
 	}
-	apiExec, ok := e.Executors["api"]
-	if !ok {
-		return "", false
+	for attempt := 0; ; attempt++ {
+		e.partial.Reset()
+		result, err := exec.Execute(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				e.savePartial(step, report)
+			}
+			return nil, err
+		}
+		report.Cost += result.Cost
+		report.TokensIn += result.TokensIn
+		report.TokensOut += result.TokensOut
+		report.Attempts++
+
+		violations := CheckArtifact(result.Output, step.Expect, e.fileListSection(step))
+		if step.Judge != nil {
+			if _, err := parseJudgement(result.Output); err != nil {
+				violations = append(violations, err.Error())
+			}
+		}
+		if len(violations) == 0 {
+			return result, nil
+		}
+		vlog.Warn("step output does not satisfy expectations",
+			"step", step.Name, "attempt", attempt+1, "violations", strings.Join(violations, "; "))
+		if attempt >= step.Expect.MaxRetries() {
+			if step.Output != "" {
+				if writeErr := e.Run.WriteFile(step.Output+".rejected", result.Output); writeErr != nil {
+					vlog.Warn("failed to save rejected output", "file", step.Output, "err", writeErr)
+				}
+			}
+			return nil, fmt.Errorf("output does not satisfy expectations after %d attempt(s): %s",
+				attempt+1, strings.Join(violations, "; "))
+		}
+		req.Correction = &executor.Correction{
+			PreviousOutput: result.Output,
+			Feedback:       correctionPrompt(violations),
+		}
+	}
+}
+
+// savePartial writes the output streamed before a cancellation to
+// <output>.partial (<Step>.partial.md for steps without an output).
+func (e *Engine) savePartial(step types.Step, report *StepReport) {
+	if e.partial.Len() == 0 {
+		return
 	}
-	type prompter interface {
-		ResolvePrompt(name string) (string, bool)
+	file := step.Name + ".partial.md"
+	if step.Output != "" {
+		file = step.Output + ".partial"
 	}
-	if p, ok := apiExec.(prompter); ok {
-		return p.ResolvePrompt(step.PromptTemplate)
+	if err := e.Run.WriteFile(file, e.partial.String()); err != nil {
+		vlog.Warn("failed to save partial output", "file", file, "err", err)
+		return
 	}
-	return "", false
+	report.Partial = file
 }
 
 // LoadPipeline resolves a pipeline by name from user/project overrides or embedded defaults.
@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/futureCreator/vcoding/internal/types"
+)
+
+// CheckArtifact returns the rules in exp that content violates, one message per
+// rule. fileListSection is the heading of the section checked by file_list.
+func CheckArtifact(content string, exp *types.Expect, fileListSection string) []string {
+	if exp == nil {
+		return nil
+	}
+	var violations []string
+
+	for _, h := range exp.Headings {
+		if !hasHeading(content, h) {
+			violations = append(violations, fmt.Sprintf("missing required section heading %q", h))
+		}
+	}
+
+	for _, p := range exp.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("invalid pattern %q: %v", p, err))
+			continue
+		}
+		if !re.MatchString(content) {
+			violations = append(violations, fmt.Sprintf("output does not match required pattern %q", p))
+		}
+	}
+
+	if n := len(content); exp.MinLength > 0 && n < exp.MinLength {
+		violations = append(violations, fmt.Sprintf("output is too short: %d characters, at least %d required", n, exp.MinLength))
+	}
+	if n := len(content); exp.MaxLength > 0 && n > exp.MaxLength {
+		violations = append(violations, fmt.Sprintf("output is too long: %d characters, at most %d allowed", n, exp.MaxLength))
+	}
+
+	if exp.FileList {
+		if files, _ := ExtractFilesFromSection(content, fileListSection); len(files) == 0 {
+			violations = append(violations, fmt.Sprintf("the %q section must be a bullet list of file paths (e.g. \"- `internal/foo.go` — description\")", fileListSection))
+		}
+	}
+
+	if exp.JSON && !json.Valid([]byte(stripCodeFence(content))) {
+		violations = append(violations, "output is not valid JSON")
+	}
+
+	return violations
+}
+
+// fileListSection returns the heading checked by the file_list rule of step:
+// the rule's own section, else the section of the first context_filter in
+// the pipeline that reads the step's output, else "Files to Change".
+func (e *Engine) fileListSection(step types.Step) string {
+	if step.Expect != nil && step.Expect.FileListSection != "" {
+		return step.Expect.FileListSection
+	}
+	if step.Output != "" {
+		for _, s := range e.Pipeline.Steps {
+			if s.ContextFilter != nil && s.ContextFilter.From == step.Output {
+				return s.ContextFilter.SectionName()
+			}
+		}
+	}
+	return "Files to Change"
+}
+
+// hasHeading reports whether content contains a markdown heading whose text
+// equals heading, ignoring case and surrounding whitespace.
+func hasHeading(content, heading string) bool {
+	want := strings.ToLower(strings.TrimSpace(heading))
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if strings.ToLower(text) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// stripCodeFence removes a single surrounding ``` fence, which models often
+// add around JSON output.
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") {
+		return s
+	}
+	s = strings.TrimSuffix(s, "```")
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return strings.TrimSpace(s[i+1:])
+	}
+	return ""
+}
+
+// correctionPrompt builds the follow-up message asking the model to fix its output.
+func correctionPrompt(violations []string) string {
+	var sb strings.Builder
+	sb.WriteString("Your previous response does not satisfy the required output format:\n\n")
+	for _, v := range violations {
+		sb.WriteString("- ")
+		sb.WriteString(v)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nRespond again with the complete, corrected document. Output only the document.")
+	return sb.String()
+}
@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/futureCreator/vcoding/internal/executor"
+	"github.com/futureCreator/vcoding/internal/run"
+	"github.com/futureCreator/vcoding/internal/types"
+)
+
+// staticExecutor returns the same output for every request.
+type staticExecutor string
+
+func (x staticExecutor) Execute(ctx context.Context, req *executor.Request) (*executor.Result, error) {
+	return &executor.Result{Output: string(x)}, nil
+}
+
+func TestCandidatesFileListSection(t *testing.T) {
+	retries := 0
+	plan := types.Step{
+		Name:       "Plan",
+		Executor:   "api",
+		Output:     "PLAN.md",
+		Candidates: &types.Candidates{Count: 2},
+		Expect:     &types.Expect{FileList: true, Retries: &retries},
+	}
+	implement := types.Step{
+		Name:          "Implement",
+		Executor:      "api",
+		ContextFilter: &types.ContextFilter{From: "PLAN.md", Section: "Changes"},
+	}
+	e := &Engine{
+		Pipeline: &Pipeline{Steps: []types.Step{plan, implement}},
+		Run:      &run.Run{ID: "test", Dir: t.TempDir()},
+	}
+	output := staticExecutor("## Changes\n\n- `internal/a.go` — add A\n")
+
+	var report StepReport
+	err := e.runCandidates(context.Background(), output, &executor.Request{Step: plan}, &report)
+	if err != nil {
+		t.Fatalf("runCandidates: %v", err)
+	}
+	for _, c := range report.Candidates {
+		if c.Error != "" {
+			t.Errorf("candidate %s: %s", c.File, c.Error)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/futureCreator/vcoding/internal/hooks"
+	vlog "github.com/futureCreator/vcoding/internal/log"
+	"github.com/futureCreator/vcoding/internal/types"
+)
+
+// runHookEnv returns the environment passed to every hook of this run.
+func (e *Engine) runHookEnv() map[string]string {
+	return map[string]string{
+		"VCODING_RUN_ID":     e.Run.ID,
+		"VCODING_RUN_DIR":    e.Run.Dir,
+		"VCODING_PIPELINE":   e.Pipeline.Name,
+		"VCODING_INPUT_MODE": e.Run.Meta.InputMode,
+		"VCODING_INPUT_REF":  e.Run.Meta.InputRef,
+		"VCODING_TICKET":     e.Run.FilePath("TICKET.md"),
+	}
+}
+
+// stepHookEnv extends runHookEnv with metadata about step.
+func (e *Engine) stepHookEnv(step types.Step, model string) map[string]string {
+	env := e.runHookEnv()
+	env["VCODING_STEP"] = step.Name
+	env["VCODING_STEP_EXECUTOR"] = step.Executor
+	env["VCODING_STEP_MODEL"] = model
+	if step.Output != "" {
+		env["VCODING_STEP_OUTPUT"] = e.Run.FilePath(step.Output)
+	}
+	return env
+}
+
+// runPreStepHooks runs the config-wide and step pre_step hooks. When a hook
+// fails and on_pre_step_error is "skip", it returns a non-empty skip reason
+// instead of an error.
+func (e *Engine) runPreStepHooks(ctx context.Context, step types.Step, env map[string]string) (skipReason string, err error) {
+	err = e.runHooks(ctx, hooks.PreStep, env, e.Config.Hooks.PreStep, step.Hooks.PreStep)
+	if err == nil {
+		return "", nil
+	}
+	mode := step.Hooks.OnPreStepError
+	if mode == "" {
+		mode = e.Config.Hooks.OnPreStepError
+	}
+	if mode == "skip" {
+		return err.Error(), nil
+	}
+	return "", err
+}
+
+// runHooks runs hook command lists in order and returns the first failure.
+// Hooks are not run in dry-run mode.
+func (e *Engine) runHooks(ctx context.Context, event string, env map[string]string, lists ...[]string) error {
+	if e.DryRun {
+		return nil
+	}
+	runner := &hooks.Runner{LogPath: e.Run.FilePath("hooks.log")}
+	for _, cmds := range lists {
+		if err := runner.Run(ctx, event, cmds, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runHooksLogged runs hooks whose failure must not change the outcome of the
+// run (on_success, on_failure); errors are only logged.
+func (e *Engine) runHooksLogged(ctx context.Context, event string, env map[string]string, lists ...[]string) {
+	if err := e.runHooks(ctx, event, env, lists...); err != nil {
+		vlog.Warn("hook failed", "event", event, "err", err)
+	}
+}
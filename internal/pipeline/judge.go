@@ -0,0 +1,271 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/futureCreator/vcoding/internal/executor"
+	vlog "github.com/futureCreator/vcoding/internal/log"
+	"github.com/futureCreator/vcoding/internal/run"
+	"github.com/futureCreator/vcoding/internal/types"
+)
+
+// mergedWinner is the winner recorded when the judge merges candidates.
+const mergedWinner = "merged"
+
+// CandidateFile returns the file name of candidate n of an artifact,
+// e.g. PLAN.md → PLAN.candidate-2.md.
+func CandidateFile(output string, n int) string {
+	ext := path.Ext(output)
+	return fmt.Sprintf("%s.candidate-%d%s", strings.TrimSuffix(output, ext), n, ext)
+}
+
+// runCandidates executes req once per candidate, cycling through the
+// configured models, and writes each output as a candidate file. A failed
+// candidate is recorded and skipped; the step fails only if none succeed.
+func (e *Engine) runCandidates(ctx context.Context, exec executor.Executor, req *executor.Request, report *StepReport) error {
+	step := req.Step
+	c := step.Candidates
+
+	// Candidates are written under their own names, so the file list
+	// section is resolved from the artifact they stand for.
+	expect := step.Expect
+	if expect != nil && expect.FileList {
+		x := *expect
+		x.FileListSection = e.fileListSection(step)
+		expect = &x
+	}
+
+	var files []string
+	for n := 1; n <= c.N(); n++ {
+		cand := step
+		cand.Output = CandidateFile(step.Output, n)
+		cand.Expect = expect
+		if len(c.Models) > 0 {
+			cand.Model = e.resolveModel(c.Models[(n-1)%len(c.Models)])
+		}
+
+		creq := *req
+		creq.Step = cand
+		if c.Seed != nil {
+			seed := *c.Seed + n - 1
+			creq.Seed = &seed
+		}
+		if cand.Model != step.Model {
+			systemPrompt, err := e.renderPrompt(cand)
+			if err != nil {
+				return err
+			}
+			creq.SystemPrompt = systemPrompt
+		}
+
+		var cr StepReport
+		result, err := e.executeChecked(ctx, exec, &creq, &cr)
+		report.Cost += cr.Cost
+		report.TokensIn += cr.TokensIn
+		report.TokensOut += cr.TokensOut
+		report.Attempts += cr.Attempts
+		if cr.Partial != "" {
+			report.Partial = cr.Partial
+		}
+
+		res := run.CandidateResult{
+			File:      cand.Output,
+			Model:     cand.Model,
+			Cost:      cr.Cost,
+			TokensIn:  cr.TokensIn,
+			TokensOut: cr.TokensOut,
+		}
+		if err == nil {
+			err = e.Run.WriteArtifact(cand.Output, step.Name, result.Output)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			vlog.Warn("candidate failed", "step", step.Name, "candidate", n, "err", err)
+			res.Error = err.Error()
+		} else {
+			files = append(files, cand.Output)
+		}
+		report.Candidates = append(report.Candidates, res)
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("all %d candidates failed", c.N())
+	}
+	if e.candidates == nil {
+		e.candidates = map[string][]string{}
+	}
+	e.candidates[step.Output] = files
+	report.Detail = fmt.Sprintf("%d/%d candidates", len(files), c.N())
+	return nil
+}
+
+// promoteWinner parses the judge step's output and writes the winning
+// candidate, or the judge's merged version, to the judged artifact.
+func (e *Engine) promoteWinner(step types.Step, output string, report *StepReport) error {
+	from := step.Judge.From
+	files := e.candidates[from]
+
+	j, err := parseJudgement(output)
+	if e.DryRun {
+		// The judge's output is a placeholder; promote the first candidate.
+		j, err = Judgement{Winner: candidateNumber(files[0])}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	result := &run.JudgeResult{From: from, Scores: map[string]float64{}}
+	for n, score := range j.Scores {
+		result.Scores[CandidateFile(from, n)] = score
+	}
+
+	var content string
+	if j.Winner == 0 {
+		content = j.Merged
+		result.Winner = mergedWinner
+	} else {
+		winner := CandidateFile(from, j.Winner)
+		if !slices.Contains(files, winner) {
+			return fmt.Errorf("judge picked candidate %d, but there is no %s", j.Winner, winner)
+		}
+		if content, err = e.Run.ReadFile(winner); err != nil {
+			return fmt.Errorf("reading winning candidate: %w", err)
+		}
+		result.Winner = winner
+	}
+
+	if err := e.Run.WriteArtifact(from, step.Name, content); err != nil {
+		return fmt.Errorf("promoting %s: %w", result.Winner, err)
+	}
+	vlog.Debug("judge promoted candidate", "step", step.Name, "winner", result.Winner, "to", from)
+
+	report.Judge = result
+	report.Detail = fmt.Sprintf("%s ← %s", from, mergedWinner)
+	if j.Winner > 0 {
+		report.Detail = fmt.Sprintf("%s ← candidate %d", from, j.Winner)
+	}
+	return nil
+}
+
+// candidateNumber returns n for a candidate file written by CandidateFile.
+func candidateNumber(file string) int {
+	m := candidateRefRe.FindStringSubmatch(file)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// Judgement is the decision parsed from a judge step's output.
+type Judgement struct {
+	Winner int             // 1-based candidate number, 0 when candidates were merged
+	Merged string          // merged document when Winner is 0
+	Scores map[int]float64 // candidate number → score
+}
+
+var (
+	candidateRefRe   = regexp.MustCompile(`(?i)candidate[\s#_-]*(\d+)`)
+	candidateScoreRe = regexp.MustCompile(`(?i)candidate[\s#_-]*(\d+)\D*?(\d+(?:\.\d+)?)`)
+)
+
+// parseJudgement reads the Scores, Winner and Merged sections of a judge
+// output. Without a Winner section, the highest scored candidate wins.
+func parseJudgement(content string) (Judgement, error) {
+	j := Judgement{Scores: map[int]float64{}}
+
+	scores, ok := headingSection(content, "Scores")
+	if !ok {
+		scores = content
+	}
+	for _, line := range strings.Split(scores, "\n") {
+		m := candidateScoreRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		score, _ := strconv.ParseFloat(m[2], 64)
+		if _, seen := j.Scores[n]; !seen {
+			j.Scores[n] = score
+		}
+	}
+
+	if winner, ok := headingSection(content, "Winner"); ok {
+		line := firstLine(winner)
+		if strings.Contains(strings.ToLower(line), mergedWinner) {
+			merged, _ := headingSection(content, "Merged")
+			if strings.TrimSpace(merged) == "" {
+				return j, fmt.Errorf(`the winner is "Merged" but the "Merged" section is empty`)
+			}
+			j.Merged = strings.TrimSpace(merged) + "\n"
+			return j, nil
+		}
+		if m := candidateRefRe.FindStringSubmatch(line); m != nil {
+			j.Winner, _ = strconv.Atoi(m[1])
+			return j, nil
+		}
+	}
+
+	best := -1.0
+	for n, score := range j.Scores {
+		if score > best || (score == best && n < j.Winner) {
+			best, j.Winner = score, n
+		}
+	}
+	if j.Winner == 0 {
+		return j, fmt.Errorf(`the "Winner" section must name a candidate ("Candidate <n>") or "Merged"`)
+	}
+	return j, nil
+}
+
+// headingSection returns the text below the markdown heading named heading,
+// up to the next heading of the same or a higher level. The "Merged" section
+// always extends to the end of the document since it holds a full document.
+func headingSection(content, heading string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		level, text := headingLine(line)
+		if level == 0 || !strings.EqualFold(text, heading) {
+			continue
+		}
+		if strings.EqualFold(heading, "Merged") {
+			return strings.Join(lines[i+1:], "\n"), true
+		}
+		end := len(lines)
+		for k := i + 1; k < len(lines); k++ {
+			if l, _ := headingLine(lines[k]); l > 0 && l <= level {
+				end = k
+				break
+			}
+		}
+		return strings.Join(lines[i+1:end], "\n"), true
+	}
+	return "", false
+}
+
+// headingLine returns the level and text of a markdown heading line, or 0.
+func headingLine(line string) (int, string) {
+	line = strings.TrimSpace(line)
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(line[level:])
+}
+
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJudgement(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		winner  int
+		merged  string
+		scores  map[int]float64
+		wantErr string
+	}{
+		{
+			name:    "winner section",
+			content: "## Scores\n- Candidate 1: 7/10\n- Candidate 2: 9/10\n\n## Winner\nCandidate 2\n\n## Rationale\nMore complete.",
+			winner:  2,
+			scores:  map[int]float64{1: 7, 2: 9},
+		},
+		{
+			name:    "winner overrides scores",
+			content: "## Scores\nCandidate #1 — 9.5\nCandidate #2 — 6\n## Winner\n**candidate-2**, since it is safer",
+			winner:  2,
+			scores:  map[int]float64{1: 9.5, 2: 6},
+		},
+		{
+			name:    "highest score without winner section",
+			content: "Candidate 1 scores 6.\nCandidate 2 scores 8.\nCandidate 3 scores 8.",
+			winner:  2,
+			scores:  map[int]float64{1: 6, 2: 8, 3: 8},
+		},
+		{
+			name:    "first score per candidate counts",
+			content: "## Scores\nCandidate 1: 5\nCandidate 2: 4\nCandidate 1: 3",
+			winner:  1,
+			scores:  map[int]float64{1: 5, 2: 4},
+		},
+		{
+			name:    "merged",
+			content: "## Scores\nCandidate 1: 5\n## Winner\nMerged\n## Merged\n# Plan\n\n## Steps\n1. Do it\n",
+			merged:  "# Plan\n\n## Steps\n1. Do it\n",
+			scores:  map[int]float64{1: 5},
+		},
+		{
+			name:    "merged but empty",
+			content: "## Winner\nMerged\n## Merged\n\n",
+			wantErr: `"Merged" section is empty`,
+		},
+		{
+			name:    "no decision",
+			content: "Both plans look fine to me.",
+			wantErr: `must name a candidate`,
+		},
+		{
+			name:    "winner names nothing",
+			content: "## Winner\nthe second one\n",
+			wantErr: `must name a candidate`,
+		},
+		{
+			name:    "empty output",
+			content: "",
+			wantErr: `must name a candidate`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := parseJudgement(tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJudgement: %v", err)
+			}
+			if j.Winner != tt.winner || j.Merged != tt.merged {
+				t.Errorf("winner %d, merged %q; want %d, %q", j.Winner, j.Merged, tt.winner, tt.merged)
+			}
+			if len(j.Scores) != len(tt.scores) {
+				t.Errorf("scores = %v, want %v", j.Scores, tt.scores)
+			}
+			for n, score := range tt.scores {
+				if j.Scores[n] != score {
+					t.Errorf("score of candidate %d = %v, want %v", n, j.Scores[n], score)
+				}
+			}
+		})
+	}
+}
+
+func TestCandidateFile(t *testing.T) {
+	for _, tt := range []struct {
+		output string
+		n      int
+		want   string
+	}{
+		{"PLAN.md", 2, "PLAN.candidate-2.md"},
+		{"notes", 1, "notes.candidate-1"},
+		{"docs/PLAN.md", 3, "docs/PLAN.candidate-3.md"},
+	} {
+		got := CandidateFile(tt.output, tt.n)
+		if got != tt.want {
+			t.Errorf("CandidateFile(%q, %d) = %q, want %q", tt.output, tt.n, got, tt.want)
+		}
+		if n := candidateNumber(got); n != tt.n {
+			t.Errorf("candidateNumber(%q) = %d, want %d", got, n, tt.n)
+		}
+	}
+}
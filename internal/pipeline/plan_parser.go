@@ -8,6 +8,12 @@ import (
 // ExtractFilesFromPlan parses PLAN.md content and extracts file paths from
 // the "Files to Change" section.
 func ExtractFilesFromPlan(planContent string) ([]string, []string) {
+	return ExtractFilesFromSection(planContent, "Files to Change")
+}
+
+// ExtractFilesFromSection extracts file paths from the bullet list under the
+// ## or ### heading named heading. It also returns all headers found, for debugging.
+func ExtractFilesFromSection(planContent, heading string) ([]string, []string) {
 	// Find all ## or ### headers for debugging
 	headerPattern := regexp.MustCompile(`(?m)^#{2,3}\s*(.+)$`)
 	matches := headerPattern.FindAllStringSubmatch(planContent, -1)
@@ -18,8 +24,12 @@ func ExtractFilesFromPlan(planContent string) ([]string, []string) {
 		}
 	}
 
-	// Find the "Files to Change" section header (case-insensitive, flexible whitespace, ## or ###)
-	sectionPattern := regexp.MustCompile(`(?im)^#{2,3}\s*Files\s+to\s+Change\s*$`)
+	// Find the section header (case-insensitive, flexible whitespace, ## or ###)
+	words := strings.Fields(heading)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	sectionPattern := regexp.MustCompile(`(?im)^#{2,3}\s*` + strings.Join(words, `\s+`) + `\s*$`)
 	loc := sectionPattern.FindStringIndex(planContent)
 	if loc == nil {
 		return nil, allHeaders
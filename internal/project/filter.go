@@ -0,0 +1,280 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/futureCreator/vcoding/internal/config"
+)
+
+// pathFilter decides which files are eligible for the project context.
+// include_patterns and exclude_patterns use gitignore syntax against
+// repo-relative paths: "**" matches any number of directories, a pattern
+// without a slash matches at any depth, a trailing slash matches directories
+// only, and "!" negates. Within each list the last matching pattern wins.
+type pathFilter struct {
+	maxSize    int64
+	skipHidden bool
+	ignore     *ignoreMatcher
+	include    []ignoreRule
+	exclude    []ignoreRule
+	// roots are the literal leading directories of the include patterns;
+	// nil when some pattern may match at any depth.
+	roots []string
+	scope string // only files below this directory are eligible, "" for all
+}
+
+// newPathFilter compiles the patterns of cfg. For tracked files, .gitignore
+// does not apply and hidden directories are not skipped; otherwise both
+// follow respect_gitignore.
+func newPathFilter(cfg *config.ProjectCtxConfig, tracked bool) (*pathFilter, error) {
+	maxSize, err := ParseSize(cfg.MaxFileSize)
+	if err != nil {
+		return nil, fmt.Errorf("parsing max_file_size: %w", err)
+	}
+	f := &pathFilter{
+		maxSize:    maxSize,
+		skipHidden: !cfg.RespectGitignore && !tracked,
+		ignore:     newIgnoreMatcher(cfg.RespectGitignore && !tracked),
+		include:    compilePatterns(cfg.IncludePatterns, "include_patterns"),
+		exclude:    compilePatterns(cfg.ExcludePatterns, "exclude_patterns"),
+		roots:      []string{},
+		scope:      cfg.Scope,
+	}
+	for _, r := range f.include {
+		if r.negate {
+			continue
+		}
+		root := literalDir(r.pattern)
+		if !r.anchored || root == "" {
+			f.roots = nil
+			break
+		}
+		f.roots = append(f.roots, root)
+	}
+	return f, nil
+}
+
+// compilePatterns parses config patterns into rules; source names the list
+// in explanations.
+func compilePatterns(patterns []string, source string) []ignoreRule {
+	var rules []ignoreRule
+	for i, p := range patterns {
+		if r, ok := parseIgnoreLine(p); ok {
+			r.source, r.line = source, i+1
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// literalDir returns the leading directories of a pattern that contain no
+// glob characters, e.g. "internal/cli" for "/internal/cli/**/*.go".
+func literalDir(pattern string) string {
+	pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "/")
+	parts := strings.Split(pattern, "/")
+	var dirs []string
+	for _, part := range parts[:len(parts)-1] {
+		if strings.ContainsAny(part, `*?[\`) {
+			break
+		}
+		dirs = append(dirs, part)
+	}
+	return strings.Join(dirs, "/")
+}
+
+// lastMatch returns the last rule matching p, or nil.
+func lastMatch(rules []ignoreRule, p string, isDir bool) *ignoreRule {
+	var decided *ignoreRule
+	for i := range rules {
+		if rules[i].matches(p, isDir) {
+			decided = &rules[i]
+		}
+	}
+	return decided
+}
+
+// excluded returns the exclude pattern excluding p itself, or nil.
+func (f *pathFilter) excluded(p string, isDir bool) *ignoreRule {
+	if r := lastMatch(f.exclude, p, isDir); r != nil && !r.negate {
+		return r
+	}
+	return nil
+}
+
+// ignored returns the ignore file rule ignoring p itself, or nil.
+func (f *pathFilter) ignored(p string, isDir bool) *ignoreRule {
+	if r := f.ignore.match(p, isDir); r != nil && !r.negate {
+		return r
+	}
+	return nil
+}
+
+// included returns the include pattern deciding file p, looking at its
+// parent directories when no pattern matches the file itself, or nil.
+func (f *pathFilter) included(p string) *ignoreRule {
+	if r := lastMatch(f.include, p, false); r != nil {
+		return r
+	}
+	for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+		if r := lastMatch(f.include, dir, true); r != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// mayInclude reports whether an include pattern can match below dir.
+func (f *pathFilter) mayInclude(dir string) bool {
+	if f.roots == nil {
+		return true
+	}
+	for _, root := range f.roots {
+		if dir == root || strings.HasPrefix(root, dir+"/") || strings.HasPrefix(dir, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// InScope reports whether the slash-separated path p lies within scope.
+// Every path is within the empty scope.
+func InScope(p, scope string) bool {
+	return scope == "" || p == scope || strings.HasPrefix(p, scope+"/")
+}
+
+// skipDir returns why the walk does not descend into directory p, or ""
+// to descend.
+func (f *pathFilter) skipDir(p string) string {
+	name := path.Base(p)
+	switch {
+	case name == ".git":
+		return ".git is never scanned"
+	case !InScope(p, f.scope) && !InScope(f.scope, p):
+		return "outside scope " + f.scope
+	case f.excluded(p, true) != nil:
+		return "excluded by " + describeRule(f.excluded(p, true))
+	case f.skipHidden && strings.HasPrefix(name, "."):
+		return "hidden directory (respect_gitignore is off)"
+	case f.ignored(p, true) != nil:
+		return "ignored by " + describeRule(f.ignored(p, true))
+	case !f.mayInclude(p):
+		return "no include pattern can match below it"
+	}
+	return ""
+}
+
+// skipFile returns why file p of the given size is not eligible, assuming
+// its directories are not skipped, or "" if it is eligible.
+func (f *pathFilter) skipFile(p string, size int64) string {
+	if !InScope(p, f.scope) {
+		return "outside scope " + f.scope
+	}
+	if r := f.ignored(p, false); r != nil {
+		return "ignored by " + describeRule(r)
+	}
+	if r := f.excluded(p, false); r != nil {
+		return "excluded by " + describeRule(r)
+	}
+	r := f.included(p)
+	if r == nil {
+		return "no include pattern matches"
+	}
+	if r.negate {
+		return "excluded by " + describeRule(r)
+	}
+	if size > f.maxSize {
+		return fmt.Sprintf("larger than max_file_size (%s > %s)", formatSize(size), formatSize(f.maxSize))
+	}
+	return ""
+}
+
+// describeRule names a rule and where it comes from, e.g.
+// `exclude_patterns "vendor/"` or `.gitignore:3 "build/"`.
+func describeRule(r *ignoreRule) string {
+	if strings.HasSuffix(r.source, "_patterns") {
+		return fmt.Sprintf("%s %q", r.source, r.pattern)
+	}
+	return fmt.Sprintf("%s:%d %q", r.source, r.line, r.pattern)
+}
+
+// Explanation describes whether a file is eligible for the project context.
+type Explanation struct {
+	Path     string
+	Eligible bool
+	Reason   string
+}
+
+// Explain reports whether the file or directory p is eligible for the
+// project context under cfg, and which pattern, ignore file or limit decided
+// it. Eligible files are still subject to ranking and the context budgets.
+func Explain(cfg *config.ProjectCtxConfig, p string) (Explanation, error) {
+	rel, err := relativePath(p)
+	if err != nil {
+		return Explanation{}, err
+	}
+	e := Explanation{Path: rel}
+	info, err := os.Stat(filepath.FromSlash(rel))
+	if err != nil {
+		return e, err
+	}
+	filter, err := newPathFilter(cfg, cfg.TrackedOnly)
+	if err != nil {
+		return e, err
+	}
+
+	if cfg.TrackedOnly && !info.IsDir() {
+		tracked, err := TrackedFiles()
+		if err != nil {
+			return e, err
+		}
+		if !slices.Contains(tracked, rel) {
+			e.Reason = "not tracked by git (tracked_only is on)"
+			return e, nil
+		}
+	}
+	if reason := skippedDirs(filter, rel); reason != "" {
+		e.Reason = "in skipped directory " + reason
+		return e, nil
+	}
+	if info.IsDir() {
+		if reason := filter.skipDir(rel); reason != "" && rel != "." {
+			e.Reason = reason
+			return e, nil
+		}
+		e.Eligible = true
+		e.Reason = "directory is scanned"
+		return e, nil
+	}
+	if reason := filter.skipFile(rel, info.Size()); reason != "" {
+		e.Reason = reason
+		return e, nil
+	}
+	e.Eligible = true
+	e.Reason = "matches " + describeRule(filter.included(rel))
+	return e, nil
+}
+
+// relativePath converts p to a clean slash-separated path relative to the
+// working directory, rejecting paths outside of it.
+func relativePath(p string) (string, error) {
+	rel := p
+	if filepath.IsAbs(p) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		if rel, err = filepath.Rel(wd, p); err != nil {
+			return "", err
+		}
+	}
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%s is outside the project", p)
+	}
+	return rel, nil
+}
@@ -38,13 +38,14 @@ func CollectGitInfo() (*GitInfo, error) {
 	}, nil
 }
 
-// Diff returns the current staged+unstaged diff.
-func Diff() (string, error) {
-	staged, err := gitOutput("diff", "--cached")
+// Diff returns the current staged+unstaged diff, limited to scope unless
+// scope is empty.
+func Diff(scope string) (string, error) {
+	staged, err := gitOutput(append([]string{"diff", "--cached"}, pathspec(scope)...)...)
 	if err != nil {
 		return "", fmt.Errorf("getting staged diff: %w", err)
 	}
-	unstaged, err := gitOutput("diff")
+	unstaged, err := gitOutput(append([]string{"diff"}, pathspec(scope)...)...)
 	if err != nil {
 		return "", fmt.Errorf("getting unstaged diff: %w", err)
 	}
@@ -52,6 +53,106 @@ func Diff() (string, error) {
 	return combined, nil
 }
 
+// RepoName returns the repository path (e.g. "owner/repo") parsed from the
+// origin remote URL. Returns an empty string if there is no origin remote.
+func RepoName() string {
+	url, err := gitOutput("remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+	return ParseRepoPath(url)
+}
+
+// ParseRepoPath extracts the repository path from an SSH or HTTPS remote URL,
+// e.g. "git@github.com:owner/repo.git" → "owner/repo".
+func ParseRepoPath(url string) string {
+	url = strings.TrimSpace(url)
+	url = strings.TrimSuffix(url, ".git")
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+		if j := strings.Index(url, "/"); j >= 0 {
+			return url[j+1:]
+		}
+		return ""
+	}
+	if i := strings.Index(url, ":"); i >= 0 {
+		return url[i+1:]
+	}
+	return ""
+}
+
+// DiffAgainst returns the diff between the merge base of base and HEAD and
+// the working tree, i.e. everything the current branch changes relative to
+// base, limited to scope unless scope is empty.
+func DiffAgainst(base, scope string) (string, error) {
+	mergeBase, err := gitOutput("merge-base", base, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("finding merge base with %s: %w", base, err)
+	}
+	diff, err := gitOutput(append([]string{"diff", mergeBase}, pathspec(scope)...)...)
+	if err != nil {
+		return "", fmt.Errorf("getting diff against %s: %w", base, err)
+	}
+	return diff, nil
+}
+
+// Log returns the last n commits in one-line format.
+func Log(n int) (string, error) {
+	out, err := gitOutput("log", fmt.Sprintf("-n%d", n), "--date=short", "--pretty=format:%h %ad %an %s")
+	if err != nil {
+		return "", fmt.Errorf("getting git log: %w", err)
+	}
+	return out, nil
+}
+
+// TrackedFiles returns the paths of all files tracked by git.
+func TrackedFiles() ([]string, error) {
+	out, err := gitOutput("ls-files")
+	if err != nil {
+		return nil, fmt.Errorf("listing tracked files: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// RecentChanges maps files changed in the working tree or in the last n
+// commits to how recently they changed: 0 for uncommitted changes, 1 for
+// the latest commit, and so on.
+func RecentChanges(n int) (map[string]int, error) {
+	changes := map[string]int{}
+	if out, err := gitOutput("diff", "--name-only", "HEAD"); err == nil && out != "" {
+		for _, f := range strings.Split(out, "\n") {
+			changes[f] = 0
+		}
+	}
+	out, err := gitOutput("log", fmt.Sprintf("-n%d", n), "--name-only", "--format=@@")
+	if err != nil {
+		return changes, fmt.Errorf("getting recent changes: %w", err)
+	}
+	commit := 0
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case line == "@@":
+			commit++
+		case line != "":
+			if _, ok := changes[line]; !ok {
+				changes[line] = commit
+			}
+		}
+	}
+	return changes, nil
+}
+
+// pathspec returns the git arguments limiting a command to scope.
+func pathspec(scope string) []string {
+	if scope == "" {
+		return nil
+	}
+	return []string{"--", scope}
+}
+
 func isDirty() (bool, error) {
 	out, err := gitOutput("status", "--porcelain")
 	if err != nil {
@@ -0,0 +1,35 @@
+package project
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/futureCreator/vcoding/internal/config"
+)
+
+// GuidelineFiles returns the project guidance files listed in
+// project_context.guidelines that exist, in order. Each path is looked up
+// relative to the project root and, in a scoped run, relative to the scope,
+// so a service can add its own conventions to the repository's.
+func GuidelineFiles(cfg *config.ProjectCtxConfig) []string {
+	var files []string
+	seen := map[string]bool{}
+	add := func(p string) {
+		p = path.Clean(p)
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		if info, err := os.Stat(filepath.FromSlash(p)); err == nil && !info.IsDir() {
+			files = append(files, p)
+		}
+	}
+	for _, g := range cfg.Guidelines {
+		add(g)
+		if cfg.Scope != "" {
+			add(path.Join(cfg.Scope, g))
+		}
+	}
+	return files
+}
@@ -0,0 +1,207 @@
+package project
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// VcodingIgnoreFile holds gitignore-style patterns excluded from project
+// context only. It may appear in any directory, like .gitignore.
+const VcodingIgnoreFile = ".vcodingignore"
+
+// ignoreRule is a single pattern line of an ignore file.
+type ignoreRule struct {
+	base     string // slash-separated directory of the ignore file, "" for the root
+	pattern  string // original pattern text
+	source   string // ignore file path, or the config list of a project_context pattern
+	line     int    // 1-based line, or position in the config list
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool // relative to base rather than matching at any depth
+}
+
+// ignoreMatcher evaluates gitignore rules the way git does: rules from
+// deeper directories take precedence over those from their parents, and
+// within a file the last matching pattern wins.
+type ignoreMatcher struct {
+	names  []string                // ignore files read in every directory, lowest precedence first
+	global []ignoreRule            // .git/info/exclude
+	perDir map[string][]ignoreRule // directory → rules of its ignore files, loaded lazily
+}
+
+// newIgnoreMatcher returns a matcher reading names in every directory below
+// the working directory. With gitignore set, .gitignore and
+// .git/info/exclude are honored too.
+func newIgnoreMatcher(gitignore bool) *ignoreMatcher {
+	m := &ignoreMatcher{perDir: map[string][]ignoreRule{}}
+	if gitignore {
+		m.names = append(m.names, ".gitignore")
+		m.global = readIgnoreFile(filepath.Join(".git", "info", "exclude"), "")
+	}
+	m.names = append(m.names, VcodingIgnoreFile)
+	return m
+}
+
+// match returns the rule deciding p, or nil if no rule matches.
+func (m *ignoreMatcher) match(p string, isDir bool) *ignoreRule {
+	var decided *ignoreRule
+	check := func(rules []ignoreRule) {
+		for i := range rules {
+			if rules[i].matches(p, isDir) {
+				decided = &rules[i]
+			}
+		}
+	}
+	check(m.global)
+	check(m.rulesFor(""))
+	dir := ""
+	for _, part := range strings.Split(path.Dir(p), "/") {
+		if part == "." || part == "" {
+			continue
+		}
+		dir = path.Join(dir, part)
+		check(m.rulesFor(dir))
+	}
+	return decided
+}
+
+// rulesFor loads the ignore files of dir once.
+func (m *ignoreMatcher) rulesFor(dir string) []ignoreRule {
+	if rules, ok := m.perDir[dir]; ok {
+		return rules
+	}
+	var rules []ignoreRule
+	for _, name := range m.names {
+		rules = append(rules, readIgnoreFile(filepath.Join(filepath.FromSlash(dir), name), dir)...)
+	}
+	m.perDir[dir] = rules
+	return rules
+}
+
+func (r *ignoreRule) matches(p string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	rel := p
+	if r.base != "" {
+		if !strings.HasPrefix(p, r.base+"/") {
+			return false
+		}
+		rel = p[len(r.base)+1:]
+	}
+	return r.re.MatchString(rel)
+}
+
+// readIgnoreFile parses an ignore file; a missing file yields no rules.
+func readIgnoreFile(file, base string) []ignoreRule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if r, ok := parseIgnoreLine(scanner.Text()); ok {
+			r.base, r.source, r.line = base, filepath.ToSlash(file), n
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine converts one gitignore line into a rule.
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = trimTrailingSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	r := ignoreRule{pattern: line}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// A pattern with a slash other than a trailing one is relative to the
+	// ignore file's directory; otherwise it matches at any depth.
+	r.anchored = strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	prefix := "^"
+	if !r.anchored {
+		prefix = "^(?:.*/)?"
+	}
+	re, err := regexp.Compile(prefix + globToRegexp(line) + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+// globToRegexp translates gitignore glob syntax, including "**", into a
+// regular expression fragment.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// trimTrailingSpace removes trailing spaces unless escaped with a backslash.
+func trimTrailingSpace(s string) string {
+	for strings.HasSuffix(s, " ") && !strings.HasSuffix(s, `\ `) {
+		s = s[:len(s)-1]
+	}
+	if strings.HasSuffix(s, `\ `) {
+		s = s[:len(s)-2] + " "
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package project
+
+import "testing"
+
+// ruleSet parses gitignore lines as the ignore file of directory base.
+func ruleSet(t *testing.T, base string, lines ...string) []ignoreRule {
+	t.Helper()
+	var rules []ignoreRule
+	for _, l := range lines {
+		r, ok := parseIgnoreLine(l)
+		if !ok {
+			t.Fatalf("parseIgnoreLine(%q) yields no rule", l)
+		}
+		r.base = base
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	m := &ignoreMatcher{perDir: map[string][]ignoreRule{
+		"": ruleSet(t, "",
+			"*.log",
+			"!keep.log",
+			"/build",
+			"docs/*.md",
+			"tmp/",
+			"**/gen/**",
+			"a/**/z.txt",
+			`\!bang`,
+			`\#hash`,
+			"trailing.txt  ",
+		),
+		"sub": ruleSet(t, "sub",
+			"!a.log",
+			"/only.txt",
+		),
+	}}
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool // ignored
+	}{
+		{"a.log", false, true},
+		{"deep/dir/a.log", false, true},
+		{"keep.log", false, false},
+		{"deep/keep.log", false, false},
+		{"sub/a.log", false, false}, // negated by sub/.gitignore
+		{"sub/b.log", false, true},
+
+		{"build", true, true},
+		{"sub/build", true, false}, // anchored to the root
+		{"docs/a.md", false, true},
+		{"x/docs/a.md", false, false}, // a slash anchors the pattern
+		{"docs/sub/a.md", false, false},
+
+		{"tmp", true, true},
+		{"tmp", false, false}, // directories only
+		{"x/gen/y.go", false, true},
+		{"gen.go", false, false},
+		{"a/z.txt", false, true},
+		{"a/b/c/z.txt", false, true},
+		{"b/a/z.txt", false, false},
+
+		{"!bang", false, true},
+		{"#hash", false, true},
+		{"trailing.txt", false, true},
+
+		{"sub/only.txt", false, true},
+		{"only.txt", false, false},
+		{"sub/x/only.txt", false, false},
+	}
+	for _, tt := range tests {
+		r := m.match(tt.path, tt.isDir)
+		if got := r != nil && !r.negate; got != tt.want {
+			t.Errorf("%s (dir %v): ignored = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestParseIgnoreLineSkips(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "/", "!"} {
+		if _, ok := parseIgnoreLine(line); ok {
+			t.Errorf("parseIgnoreLine(%q) yields a rule", line)
+		}
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob, want string
+	}{
+		{"*.go", `[^/]*\.go`},
+		{"file?.txt", `file[^/]\.txt`},
+		{"[!a-c]x", `[^a-c]x`},
+		{"[abc", `\[abc`},
+		{"**/x", `(?:.*/)?x`},
+		{"x/**", `x/.*`},
+		{"a/**/b", `a/(?:.*/)?b`},
+	}
+	for _, tt := range tests {
+		if got := globToRegexp(tt.glob); got != tt.want {
+			t.Errorf("globToRegexp(%q) = %q, want %q", tt.glob, got, tt.want)
+		}
+	}
+}
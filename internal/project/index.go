@@ -0,0 +1,256 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/futureCreator/vcoding/internal/config"
+)
+
+// IndexDir is the directory of the on-disk code index, relative to the
+// project root.
+var IndexDir = filepath.Join(".vcoding", "index")
+
+const (
+	indexFile    = "index.json"
+	indexVersion = 1
+)
+
+// Index is an incremental on-disk index of the project context files. It
+// keeps the term frequencies of every eligible file and an inverted index
+// from terms to files, so runs rank files without re-reading the repository.
+type Index struct {
+	Version  int                     `json:"version"`
+	Updated  time.Time               `json:"updated"`
+	Files    map[string]*IndexedFile `json:"files"`
+	Postings map[string][]string     `json:"postings"` // term → files containing it, sorted
+}
+
+// IndexedFile is the index entry of a single file.
+type IndexedFile struct {
+	Hash    string         `json:"hash"` // SHA-256 of the content
+	ModTime time.Time      `json:"mtime"`
+	Size    int64          `json:"size"`
+	Tokens  int            `json:"tokens"` // estimated tokens of the content
+	Length  int            `json:"length"` // number of terms
+	Terms   map[string]int `json:"terms"`  // term frequencies of the content and path
+}
+
+// IndexStats counts the files of an index and the changes of an update.
+type IndexStats struct {
+	Files   int
+	Added   int
+	Updated int
+	Removed int
+}
+
+// Changed reports whether the update added, updated or removed any file.
+func (s IndexStats) Changed() bool {
+	return s.Added+s.Updated+s.Removed > 0
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{Version: indexVersion, Files: map[string]*IndexedFile{}, Postings: map[string][]string{}}
+}
+
+// IndexPath returns the path of the index file.
+func IndexPath() string {
+	return filepath.Join(IndexDir, indexFile)
+}
+
+// LoadIndex reads the index from IndexDir. It returns nil and no error when
+// no index has been built.
+func LoadIndex() (*Index, error) {
+	data, err := os.ReadFile(IndexPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+	idx := NewIndex()
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("parsing index: %w", err)
+	}
+	if idx.Version != indexVersion {
+		return nil, fmt.Errorf("index version %d is not supported (want %d); rebuild it with 'vcoding index build --force'", idx.Version, indexVersion)
+	}
+	return idx, nil
+}
+
+// Save writes the index to IndexDir, replacing the previous one atomically.
+func (idx *Index) Save() error {
+	if err := os.MkdirAll(IndexDir, 0755); err != nil {
+		return fmt.Errorf("creating index dir: %w", err)
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	path := IndexPath()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing index: %w", err)
+	}
+	return nil
+}
+
+// Update brings the index in line with the files eligible under cfg. Only
+// files whose size or modification time changed are read again; files that
+// are gone or no longer eligible are dropped. The index always covers the
+// whole project: scope is ignored.
+func (idx *Index) Update(cfg *config.ProjectCtxConfig) (IndexStats, error) {
+	var stats IndexStats
+	seen := map[string]bool{}
+	err := walkEligible(unscoped(cfg), func(rel string, info os.FileInfo) bool {
+		old := idx.Files[rel]
+		if old != nil && old.Size == info.Size() && old.ModTime.Equal(info.ModTime()) {
+			seen[rel] = true
+			return len(seen) < maxCandidates
+		}
+		content, err := os.ReadFile(filepath.FromSlash(rel))
+		if err != nil {
+			return true
+		}
+		seen[rel] = true
+
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:])
+		if old != nil && old.Hash == hash {
+			// Touched but unchanged.
+			old.ModTime, old.Size = info.ModTime(), info.Size()
+			return len(seen) < maxCandidates
+		}
+		tf, length := termFrequencies(string(content) + " " + rel)
+		idx.Files[rel] = &IndexedFile{
+			Hash:    hash,
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+			Tokens:  estimateTokens(string(content)),
+			Length:  length,
+			Terms:   tf,
+		}
+		if old == nil {
+			stats.Added++
+		} else {
+			stats.Updated++
+		}
+		return len(seen) < maxCandidates
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	for p := range idx.Files {
+		if !seen[p] {
+			delete(idx.Files, p)
+			stats.Removed++
+		}
+	}
+	if stats.Changed() {
+		idx.rebuildPostings()
+	}
+	idx.Updated = time.Now()
+	stats.Files = len(idx.Files)
+	return stats, nil
+}
+
+// Stale reports the changes Update would make, judged by file size and
+// modification time only, without reading or modifying anything.
+func (idx *Index) Stale(cfg *config.ProjectCtxConfig) (IndexStats, error) {
+	stats := IndexStats{Files: len(idx.Files)}
+	seen := map[string]bool{}
+	err := walkEligible(unscoped(cfg), func(rel string, info os.FileInfo) bool {
+		seen[rel] = true
+		old := idx.Files[rel]
+		switch {
+		case old == nil:
+			stats.Added++
+		case old.Size != info.Size() || !old.ModTime.Equal(info.ModTime()):
+			stats.Updated++
+		}
+		return len(seen) < maxCandidates
+	})
+	if err != nil {
+		return stats, err
+	}
+	for p := range idx.Files {
+		if !seen[p] {
+			stats.Removed++
+		}
+	}
+	return stats, nil
+}
+
+// unscoped returns cfg without its scope.
+func unscoped(cfg *config.ProjectCtxConfig) *config.ProjectCtxConfig {
+	c := *cfg
+	c.Scope = ""
+	return &c
+}
+
+// rebuildPostings recomputes the inverted index from the file entries.
+func (idx *Index) rebuildPostings() {
+	idx.Postings = map[string][]string{}
+	for p, f := range idx.Files {
+		for t := range f.Terms {
+			idx.Postings[t] = append(idx.Postings[t], p)
+		}
+	}
+	for _, paths := range idx.Postings {
+		sort.Strings(paths)
+	}
+}
+
+// Rank is Rank over the indexed files within scope.
+func (idx *Index) Rank(query, scope string, recent map[string]int) []FileScore {
+	docs := make([]document, 0, len(idx.Files))
+	for p, f := range idx.Files {
+		if !InScope(p, scope) {
+			continue
+		}
+		docs = append(docs, document{path: p, tf: f.Terms, length: f.Length, tokens: f.Tokens})
+	}
+	df := map[string]int{}
+	for _, t := range uniqueTerms(Terms(query)) {
+		df[t] = len(idx.Postings[t])
+	}
+	return rankDocuments(docs, df, query, recent)
+}
+
+// Select is Select for indexed files: only the files considered for the
+// max_files and max_tokens budgets of cfg are read, in the order of scores.
+// In outline mode, Go files after the first full_files are outlined.
+func (idx *Index) Select(cfg *config.ProjectCtxConfig, scores []FileScore) []FileEntry {
+	order := make([]string, len(scores))
+	for i, s := range scores {
+		order[i] = s.Path
+	}
+	return selectOrdered(order, scores, cfg.MaxFiles, cfg.MaxTokens, func(i int, p string) (FileEntry, bool) {
+		content, err := os.ReadFile(filepath.FromSlash(p))
+		if err != nil {
+			return FileEntry{}, false
+		}
+		f := FileEntry{Path: p, Content: string(content)}
+		if cfg.Mode == "outline" && i >= cfg.FullFiles {
+			f = outlineEntry(f)
+		}
+		return f, true
+	})
+}
+
+// TermCount returns the number of distinct terms in the inverted index.
+func (idx *Index) TermCount() int {
+	return len(idx.Postings)
+}
@@ -0,0 +1,153 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"strings"
+)
+
+// maxOutlineValue is the longest const/var initializer kept in an outline;
+// longer ones are replaced by "…".
+const maxOutlineValue = 60
+
+// Outline returns an outline of Go source: the package clause, imports,
+// type declarations, consts and vars, and function signatures, each with its
+// doc comment and line range. Function bodies are omitted.
+func Outline(filename, src string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// Outline: function bodies omitted.\n")
+	writeDoc(&sb, file.Doc)
+	fmt.Fprintf(&sb, "package %s\n", file.Name.Name)
+
+	for _, decl := range file.Decls {
+		var text string
+		var doc *ast.CommentGroup
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			doc = d.Doc
+			fn := *d
+			fn.Doc, fn.Body = nil, nil
+			text = printNode(fset, &fn)
+		case *ast.GenDecl:
+			doc = d.Doc
+			gen := *d
+			gen.Doc = nil
+			if d.Tok == token.CONST || d.Tok == token.VAR {
+				gen.Specs = elideValues(fset, d.Specs)
+			}
+			// Keep field and method comments of type declarations.
+			text = printNode(fset, &printer.CommentedNode{Node: &gen, Comments: file.Comments})
+		default:
+			continue
+		}
+
+		sb.WriteString("\n")
+		writeDoc(&sb, doc)
+		start := fset.Position(decl.Pos()).Line
+		end := fset.Position(decl.End()).Line
+		lines := fmt.Sprintf("L%d", start)
+		if end > start {
+			lines += fmt.Sprintf("-%d", end)
+		}
+		first, rest, _ := strings.Cut(text, "\n")
+		fmt.Fprintf(&sb, "%s // %s\n", first, lines)
+		if rest != "" {
+			sb.WriteString(rest)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// Outlines replaces Go files with their outlines, except the first full
+// files in relevance order (walk order if scores is nil). Files that fail
+// to parse are kept in full.
+func Outlines(files []FileEntry, scores []FileScore, full int) []FileEntry {
+	rank := make(map[string]int, len(files))
+	if scores != nil {
+		for i, s := range scores {
+			rank[s.Path] = i
+		}
+	} else {
+		for i, f := range files {
+			rank[f.Path] = i
+		}
+	}
+
+	result := make([]FileEntry, len(files))
+	for i, f := range files {
+		result[i] = f
+		if rank[f.Path] >= full {
+			result[i] = outlineEntry(f)
+		}
+	}
+	return result
+}
+
+// outlineEntry returns f with a Go file's content replaced by its outline.
+// Other files, and Go files that fail to parse, are returned unchanged.
+func outlineEntry(f FileEntry) FileEntry {
+	if path.Ext(f.Path) != ".go" {
+		return f
+	}
+	outline, err := Outline(f.Path, f.Content)
+	if err != nil {
+		return f
+	}
+	return FileEntry{Path: f.Path, Content: outline, Outline: true}
+}
+
+// elideValues returns copies of const/var specs whose long initializers are
+// replaced by "…".
+func elideValues(fset *token.FileSet, specs []ast.Spec) []ast.Spec {
+	result := make([]ast.Spec, len(specs))
+	for i, spec := range specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			result[i] = spec
+			continue
+		}
+		short := *vs
+		short.Values = make([]ast.Expr, len(vs.Values))
+		for j, v := range vs.Values {
+			short.Values[j] = v
+			if len(printNode(fset, v)) > maxOutlineValue {
+				short.Values[j] = &ast.Ident{NamePos: v.Pos(), Name: "…"}
+			}
+		}
+		result[i] = &short
+	}
+	return result
+}
+
+func writeDoc(sb *strings.Builder, doc *ast.CommentGroup) {
+	if doc == nil {
+		return
+	}
+	for _, c := range doc.List {
+		sb.WriteString(c.Text)
+		sb.WriteString("\n")
+	}
+}
+
+// printConfig matches gofmt's layout.
+var printConfig = printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+
+func printNode(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printConfig.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,296 @@
+package project
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Ranking weights. BM25 content scores typically range from 0 to ~20.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	pathTermWeight = 1.5 // per ticket term found in the file path
+	mentionWeight  = 10  // file path or name appears in the ticket
+	recentWeight   = 2   // file changed in the working tree; decays over RecentCommits
+)
+
+// RecentCommits is the number of commits considered for the recency bonus.
+const RecentCommits = 30
+
+// FileScore records how a file ranked against the ticket.
+type FileScore struct {
+	Path      string  `json:"path"`
+	Score     float64 `json:"score"`
+	BM25      float64 `json:"bm25"`
+	PathMatch float64 `json:"path_match,omitempty"`
+	Mentioned bool    `json:"mentioned,omitempty"`
+	Recent    float64 `json:"recent,omitempty"`
+	Tokens    int     `json:"tokens"`
+	Outline   bool    `json:"outline,omitempty"`
+	Selected  bool    `json:"selected"`
+}
+
+// document is a file prepared for ranking.
+type document struct {
+	path   string
+	tf     map[string]int // term frequencies of the content and path
+	length int            // number of terms
+	tokens int
+}
+
+// Rank scores files against query, usually the ticket title and body, and
+// returns the scores in descending order. Scores combine BM25 over file
+// contents and paths, ticket terms in the path, files mentioned by the
+// ticket, and recent changes (see RecentChanges; may be nil).
+func Rank(files []FileEntry, query string, recent map[string]int) []FileScore {
+	docs := make([]document, len(files))
+	df := map[string]int{}
+	for i, f := range files {
+		tf, length := termFrequencies(f.Content + " " + f.Path)
+		for t := range tf {
+			df[t]++
+		}
+		docs[i] = document{path: f.Path, tf: tf, length: length, tokens: estimateTokens(f.Content)}
+	}
+	return rankDocuments(docs, df, query, recent)
+}
+
+// rankDocuments implements Rank given the document frequency of each term.
+func rankDocuments(docs []document, df map[string]int, query string, recent map[string]int) []FileScore {
+	queryTerms := uniqueTerms(Terms(query))
+	lowerQuery := strings.ToLower(query)
+
+	totalLen := 0
+	for _, d := range docs {
+		totalLen += d.length
+	}
+	avgLen := 1.0
+	if len(docs) > 0 && totalLen > 0 {
+		avgLen = float64(totalLen) / float64(len(docs))
+	}
+
+	n := float64(len(docs))
+	scores := make([]FileScore, len(docs))
+	for i, d := range docs {
+		s := FileScore{Path: d.path, Tokens: d.tokens}
+
+		norm := bm25K1 * (1 - bm25B + bm25B*float64(d.length)/avgLen)
+		for _, t := range queryTerms {
+			tf := float64(d.tf[t])
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[t])+0.5)/(float64(df[t])+0.5))
+			s.BM25 += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+
+		pathTerms := uniqueTerms(Terms(d.path))
+		for _, t := range queryTerms {
+			for _, p := range pathTerms {
+				if t == p {
+					s.PathMatch += pathTermWeight
+				}
+			}
+		}
+
+		s.Mentioned = mentions(lowerQuery, d.path)
+		if age, ok := recent[d.path]; ok {
+			s.Recent = recentWeight * (1 - float64(age)/float64(RecentCommits+1))
+		}
+
+		s.Score = s.BM25 + s.PathMatch + s.Recent
+		if s.Mentioned {
+			s.Score += mentionWeight
+		}
+		scores[i] = s
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Path < scores[j].Path
+	})
+	return scores
+}
+
+// Select picks files in the order of scores (walk order if scores is nil)
+// until maxFiles files are chosen; files that would exceed maxTokens are
+// skipped so smaller ones can still fill the budget. Chosen scores are
+// marked Selected. Zero limits are unlimited.
+func Select(files []FileEntry, scores []FileScore, maxFiles, maxTokens int) []FileEntry {
+	byPath := make(map[string]FileEntry, len(files))
+	order := make([]string, 0, len(files))
+	for _, f := range files {
+		byPath[f.Path] = f
+		order = append(order, f.Path)
+	}
+	if scores != nil {
+		order = order[:0]
+		for _, s := range scores {
+			order = append(order, s.Path)
+		}
+	}
+	return selectOrdered(order, scores, maxFiles, maxTokens, func(i int, p string) (FileEntry, bool) {
+		f, ok := byPath[p]
+		return f, ok
+	})
+}
+
+// selectOrdered implements Select for files obtained from load, which
+// receives each path with its position in order.
+func selectOrdered(order []string, scores []FileScore, maxFiles, maxTokens int, load func(i int, path string) (FileEntry, bool)) []FileEntry {
+	var selected []FileEntry
+	used := 0
+	for i, p := range order {
+		if maxFiles > 0 && len(selected) >= maxFiles {
+			break
+		}
+		f, ok := load(i, p)
+		if !ok {
+			continue
+		}
+		tokens := estimateTokens(f.Content)
+		if scores != nil {
+			scores[i].Tokens, scores[i].Outline = tokens, f.Outline
+		}
+		if maxTokens > 0 && used+tokens > maxTokens {
+			continue
+		}
+		used += tokens
+		selected = append(selected, f)
+		if scores != nil {
+			scores[i].Selected = true
+		}
+	}
+	return selected
+}
+
+var wordRe = regexp.MustCompile(`[A-Za-z][A-Za-z0-9]*`)
+
+// stopWords are frequent English and code words that carry no relevance signal.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"from": true, "into": true, "when": true, "should": true, "would": true, "will": true,
+	"are": true, "was": true, "not": true, "but": true, "can": true, "use": true,
+	"have": true, "has": true, "its": true, "our": true, "all": true, "any": true,
+	"also": true, "than": true, "then": true, "there": true, "their": true, "which": true,
+	"func": true, "return": true, "var": true, "const": true, "type": true, "import": true,
+	"package": true, "nil": true, "true": true, "false": true, "err": true, "string": true,
+	"int": true, "let": true, "def": true, "self": true, "else": true, "struct": true,
+}
+
+// Terms splits text into lowercase search terms. Identifiers are split at
+// camelCase and snake_case boundaries; short words and stop words are dropped.
+func Terms(text string) []string {
+	var terms []string
+	for _, word := range wordRe.FindAllString(text, -1) {
+		for _, part := range splitCamel(word) {
+			part = strings.ToLower(part)
+			if len(part) < 3 || stopWords[part] {
+				continue
+			}
+			terms = append(terms, part)
+		}
+	}
+	return terms
+}
+
+// splitCamel splits "parseHTTPRequest" into "parse", "HTTP", "Request".
+func splitCamel(word string) []string {
+	runes := []rune(word)
+	var parts []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		lowerToUpper := unicode.IsLower(prev) && unicode.IsUpper(cur)
+		acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// termFrequencies counts the terms of text and returns them with their total.
+func termFrequencies(text string) (map[string]int, int) {
+	tf := map[string]int{}
+	length := 0
+	for _, t := range Terms(text) {
+		tf[t]++
+		length++
+	}
+	return tf, length
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// mentions reports whether the lowercased query names the file by path or
+// by file name. Either must stand alone: "a.go" is not mentioned by
+// "data.go" or "cmd/a.go".
+func mentions(lowerQuery, file string) bool {
+	file = strings.ToLower(file)
+	// A path with directories may follow a parent directory, a file name
+	// only "./".
+	if strings.Contains(file, "/") && containsBounded(lowerQuery, file, true) {
+		return true
+	}
+	return containsBounded(lowerQuery, path.Base(file), false)
+}
+
+// containsBounded reports whether s contains sub with no path characters
+// adjoining it. A '/' may precede sub if afterSlash is true, or as part of
+// a leading "./".
+func containsBounded(s, sub string, afterSlash bool) bool {
+	idx := strings.Index(s, sub)
+	for idx >= 0 {
+		before := idx == 0 || !isPathChar(rune(s[idx-1])) && (s[idx-1] != '/' || afterSlash || dotSlashAt(s, idx-2))
+		end := idx + len(sub)
+		after := end == len(s) || !isPathChar(rune(s[end])) || sentenceEnd(s, end)
+		if before && after {
+			return true
+		}
+		next := strings.Index(s[idx+1:], sub)
+		if next < 0 {
+			break
+		}
+		idx += next + 1
+	}
+	return false
+}
+
+// sentenceEnd reports whether s holds punctuation ending a sentence at i,
+// such as the period in "see a.go.".
+func sentenceEnd(s string, i int) bool {
+	return s[i] == '.' && (i+1 == len(s) || !isPathChar(rune(s[i+1])))
+}
+
+// dotSlashAt reports whether s holds a standalone "./" at i.
+func dotSlashAt(s string, i int) bool {
+	return i >= 0 && strings.HasPrefix(s[i:], "./") && (i == 0 || !isPathChar(rune(s[i-1])) && s[i-1] != '/')
+}
+
+func isPathChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+// estimateTokens uses the same rough estimate as the pipeline: 1 token ≈ 4 characters.
+func estimateTokens(text string) int {
+	return len(text) / 4
+}
@@ -0,0 +1,85 @@
+package project
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		query, file string
+		want        bool
+	}{
+		{"fix a.go", "a.go", true},
+		{"fix ./a.go please", "a.go", true},
+		{"`a.go`", "a.go", true},
+		{"fix data.go", "a.go", false},
+		{"fix cmd/a.go", "a.go", false},
+		{"fix a.go.bak", "a.go", false},
+		{"see a.go.", "a.go", true},
+		{"see a.go..", "a.go", false},
+		{"see internal/cli/pick.go", "internal/cli/pick.go", true},
+		{"see ./internal/cli/pick.go", "internal/cli/pick.go", true},
+		{"see vendor/internal/cli/pick.go", "internal/cli/pick.go", true},
+		{"pick.go is broken", "internal/cli/pick.go", true},
+		{"see internal/cli/pick.go2", "internal/cli/pick.go", false},
+		{"see internal/cli/mypick.go", "internal/cli/pick.go", false},
+		{"README.md is stale", "README.md", true},
+		{"Update internal/cli/pick.go. Then test.", "internal/cli/pick.go", true},
+	}
+	for _, tt := range tests {
+		lower := strings.ToLower(tt.query)
+		if got := mentions(lower, tt.file); got != tt.want {
+			t.Errorf("mentions(%q, %q) = %v, want %v", tt.query, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"parseHTTPRequest", []string{"parse", "http", "request"}},
+		{"max_file_size", []string{"max", "file", "size"}},
+		{"the func returns an error", []string{"returns", "error"}},
+		{"Fix the ID in v2", []string{"fix"}},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.text); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("Terms(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	files := []FileEntry{
+		{Path: "internal/auth/login.go", Content: "func Login(user, password string) error { return checkPassword(user, password) }"},
+		{Path: "internal/report/export.go", Content: "func Export(rows []Row) { writeCSV(rows) }"},
+		{Path: "README.md", Content: "vcoding runs pipelines."},
+	}
+	query := "Login fails when the password contains unicode"
+
+	scores := Rank(files, query, nil)
+	if scores[0].Path != "internal/auth/login.go" {
+		t.Errorf("first = %s, want internal/auth/login.go (scores %+v)", scores[0].Path, scores)
+	}
+
+	// Mentioned and recently changed files move up.
+	scores = Rank(files, query+"\nSee README.md.", map[string]int{"internal/report/export.go": 0})
+	for _, s := range scores {
+		switch s.Path {
+		case "README.md":
+			if !s.Mentioned {
+				t.Errorf("README.md not marked mentioned")
+			}
+		case "internal/report/export.go":
+			if s.Recent != recentWeight {
+				t.Errorf("recent bonus = %v, want %v", s.Recent, recentWeight)
+			}
+		}
+	}
+	if scores[len(scores)-1].Path == "README.md" {
+		t.Errorf("mentioned README.md ranked last: %+v", scores)
+	}
+}
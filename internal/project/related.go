@@ -0,0 +1,260 @@
+package project
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/futureCreator/vcoding/internal/config"
+)
+
+// maxCallerNames bounds the identifiers listed in a caller's reason.
+const maxCallerNames = 4
+
+// Caller is a Go file that references exported identifiers declared in a set
+// of changed files.
+type Caller struct {
+	Path  string
+	Names []string // referenced identifiers, e.g. "project.Collect"
+	Refs  int      // number of references
+}
+
+// Reason describes why the file was picked, e.g. "uses project.Collect, project.Rank".
+func (c Caller) Reason() string {
+	names := c.Names
+	more := ""
+	if len(names) > maxCallerNames {
+		more = fmt.Sprintf(", +%d more", len(names)-maxCallerNames)
+		names = names[:maxCallerNames]
+	}
+	return "uses " + strings.Join(names, ", ") + more
+}
+
+// goPackage is a package changed by a set of Go files.
+type goPackage struct {
+	dir        string // slash-separated directory
+	name       string // package name
+	importPath string
+	moduleRoot string          // directory holding go.mod, "." for the repository root
+	exported   map[string]bool // exported package-level identifiers of the changed files
+}
+
+// GoCallers returns the Go files eligible under cfg, in the same module as
+// one of the changed files, that reference exported package-level
+// identifiers those files declare: importers through a qualified reference,
+// and other files of the same package directly. Changed files themselves
+// are not returned. Callers are ordered by number of references, most first.
+func GoCallers(cfg *config.ProjectCtxConfig, changed []string) ([]Caller, error) {
+	skip := map[string]bool{}
+	for _, f := range changed {
+		skip[strings.TrimPrefix(f, "./")] = true
+	}
+	pkgs := changedPackages(changed)
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	var callers []Caller
+	err := walkEligible(cfg, func(rel string, info os.FileInfo) bool {
+		if path.Ext(rel) != ".go" || skip[rel] {
+			return true
+		}
+		if c, ok := callerOf(rel, pkgs); ok {
+			callers = append(callers, c)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(callers, func(i, j int) bool {
+		if callers[i].Refs != callers[j].Refs {
+			return callers[i].Refs > callers[j].Refs
+		}
+		return callers[i].Path < callers[j].Path
+	})
+	return callers, nil
+}
+
+// changedPackages parses the changed Go files and groups their exported
+// identifiers by package. Files outside a Go module, or that fail to parse,
+// are ignored.
+func changedPackages(changed []string) []*goPackage {
+	byDir := map[string]*goPackage{}
+	var pkgs []*goPackage
+	for _, f := range changed {
+		f = strings.TrimPrefix(f, "./")
+		if path.Ext(f) != ".go" || strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(filepath.FromSlash(f))
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), f, src, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		dir := path.Dir(f)
+		pkg := byDir[dir]
+		if pkg == nil {
+			root, module := findModule(dir)
+			if module == "" {
+				continue
+			}
+			importPath := module
+			if dir != root {
+				sub := dir
+				if root != "." {
+					sub = strings.TrimPrefix(dir, root+"/")
+				}
+				importPath += "/" + sub
+			}
+			pkg = &goPackage{dir: dir, name: file.Name.Name, importPath: importPath, moduleRoot: root, exported: map[string]bool{}}
+			byDir[dir] = pkg
+			pkgs = append(pkgs, pkg)
+		}
+		for _, name := range exportedNames(file) {
+			pkg.exported[name] = true
+		}
+	}
+	return pkgs
+}
+
+// exportedNames returns the exported package-level identifiers declared in
+// file. Methods are left out: calls to them cannot be told apart without
+// type information.
+func exportedNames(file *ast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.IsExported() {
+				names = append(names, d.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						names = append(names, s.Name.Name)
+					}
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.IsExported() {
+							names = append(names, n.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// findModule returns the directory of the go.mod governing dir and its
+// module path, or "" if there is none within the repository.
+func findModule(dir string) (root, module string) {
+	for d := dir; ; d = path.Dir(d) {
+		data, err := os.ReadFile(filepath.Join(filepath.FromSlash(d), "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					return d, strings.Trim(strings.TrimSpace(rest), `"`)
+				}
+			}
+			return "", ""
+		}
+		if d == "." || d == "/" {
+			return "", ""
+		}
+	}
+}
+
+// callerOf reports whether Go file rel references identifiers of pkgs.
+func callerOf(rel string, pkgs []*goPackage) (Caller, bool) {
+	dir := path.Dir(rel)
+	var candidates []*goPackage
+	for _, pkg := range pkgs {
+		if pkg.moduleRoot == "." || InScope(rel, pkg.moduleRoot) {
+			candidates = append(candidates, pkg)
+		}
+	}
+	if len(candidates) == 0 {
+		return Caller{}, false
+	}
+
+	src, err := os.ReadFile(filepath.FromSlash(rel))
+	if err != nil {
+		return Caller{}, false
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), rel, src, parser.SkipObjectResolution)
+	if err != nil {
+		return Caller{}, false
+	}
+
+	// Identifiers referenced unqualified (same package) or through the local
+	// name of an import, keyed by that name.
+	var direct *goPackage
+	qualified := map[string]*goPackage{}
+	for _, pkg := range candidates {
+		if pkg.dir == dir && pkg.name == strings.TrimSuffix(file.Name.Name, "_test") {
+			direct = pkg
+		}
+		for _, imp := range file.Imports {
+			if strings.Trim(imp.Path.Value, `"`) != pkg.importPath {
+				continue
+			}
+			local := pkg.name
+			if imp.Name != nil {
+				local = imp.Name.Name
+			}
+			if local != "_" && local != "." {
+				qualified[local] = pkg
+			}
+		}
+	}
+	if direct == nil && len(qualified) == 0 {
+		return Caller{}, false
+	}
+
+	c := Caller{Path: rel}
+	seen := map[string]bool{}
+	record := func(name string) {
+		c.Refs++
+		if !seen[name] {
+			seen[name] = true
+			c.Names = append(c.Names, name)
+		}
+	}
+	var inspect func(n ast.Node) bool
+	inspect = func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.SelectorExpr:
+			if id, ok := x.X.(*ast.Ident); ok {
+				if pkg := qualified[id.Name]; pkg != nil && pkg.exported[x.Sel.Name] {
+					record(id.Name + "." + x.Sel.Name)
+				}
+			}
+			// The selected name is a field or method, not a package-level identifier.
+			ast.Inspect(x.X, inspect)
+			return false
+		case *ast.Ident:
+			if direct != nil && direct.exported[x.Name] {
+				record(direct.name + "." + x.Name)
+			}
+		}
+		return true
+	}
+	ast.Inspect(file, inspect)
+	if c.Refs == 0 {
+		return Caller{}, false
+	}
+	return c, true
+}
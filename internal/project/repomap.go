@@ -0,0 +1,307 @@
+package project
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/futureCreator/vcoding/internal/config"
+)
+
+// mapSymbolLimits are the per-file symbol counts tried, most detailed first,
+// until the map fits its token budget.
+var mapSymbolLimits = []int{8, 4, 2, 0}
+
+// mapFile is one file of the repository map.
+type mapFile struct {
+	path    string
+	size    int64
+	lang    string
+	symbols []string
+}
+
+// languages maps file extensions to the language shown in the repository map.
+var languages = map[string]string{
+	".go":    "Go",
+	".py":    "Python",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".rs":    "Rust",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".rb":    "Ruby",
+	".c":     "C",
+	".h":     "C",
+	".cpp":   "C++",
+	".cs":    "C#",
+	".swift": "Swift",
+	".sh":    "Shell",
+	".sql":   "SQL",
+	".proto": "Protobuf",
+	".md":    "Markdown",
+	".yaml":  "YAML",
+	".yml":   "YAML",
+	".json":  "JSON",
+	".toml":  "TOML",
+}
+
+// symbolPatterns find top-level declarations in languages without a parser.
+// The first non-empty submatch is the symbol name.
+var symbolPatterns = map[string]*regexp.Regexp{
+	"Python":     regexp.MustCompile(`(?m)^(?:async\s+)?(?:def|class)\s+(\w+)`),
+	"TypeScript": regexp.MustCompile(`(?m)^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:async\s+)?(?:function\*?|class|interface|type|enum)\s+(\w+)|^export\s+(?:const|let)\s+(\w+)`),
+	"JavaScript": regexp.MustCompile(`(?m)^(?:export\s+)?(?:default\s+)?(?:async\s+)?(?:function\*?|class)\s+(\w+)|^export\s+(?:const|let)\s+(\w+)`),
+	"Rust":       regexp.MustCompile(`(?m)^(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:fn|struct|enum|trait|type|mod)\s+(\w+)`),
+	"Markdown":   regexp.MustCompile(`(?m)^#{1,2}\s+(.+?)\s*$`),
+}
+
+// RepoMap renders a tree of the files below root, respecting the ignore
+// files and exclude patterns of cfg, annotated with each file's language,
+// size and top-level symbols. Detail is reduced until the map fits within
+// maxTokens (0 = unlimited): fewer symbols per file, then directories only.
+func RepoMap(cfg *config.ProjectCtxConfig, root string, maxTokens int) (string, error) {
+	files, err := collectMap(cfg, root)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", nil
+	}
+
+	var out string
+	for _, limit := range mapSymbolLimits {
+		out = renderMap(files, limit)
+		if maxTokens <= 0 || estimateTokens(out) <= maxTokens {
+			return out, nil
+		}
+	}
+	out = renderDirs(files)
+	if maxTokens > 0 && estimateTokens(out) > maxTokens {
+		out = truncateLines(out, maxTokens*4)
+	}
+	return out, nil
+}
+
+// collectMap walks root like Collect but keeps every non-ignored file,
+// regardless of include patterns. Symbols are read from files within the
+// max_file_size limit.
+func collectMap(cfg *config.ProjectCtxConfig, root string) ([]mapFile, error) {
+	filter, err := newPathFilter(cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	start := filepath.Clean(filepath.FromSlash(root))
+
+	var files []mapFile
+	err = filepath.Walk(start, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // skip unreadable paths
+		}
+		rel := filepath.ToSlash(p)
+		if info.IsDir() {
+			if p == start {
+				return nil
+			}
+			if info.Name() == ".git" || filter.excluded(rel, true) != nil || filter.ignored(rel, true) != nil {
+				return filepath.SkipDir
+			}
+			if filter.skipHidden && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filter.ignored(rel, false) != nil || filter.excluded(rel, false) != nil {
+			return nil
+		}
+
+		f := mapFile{path: rel, size: info.Size(), lang: languages[strings.ToLower(path.Ext(rel))]}
+		if f.lang != "" && f.size <= filter.maxSize {
+			if content, err := os.ReadFile(p); err == nil {
+				f.symbols = symbols(rel, f.lang, string(content))
+			}
+		}
+		files = append(files, f)
+		if len(files) >= maxCandidates {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+// symbols returns the top-level declarations of a file in source order.
+func symbols(filename, lang, content string) []string {
+	if lang == "Go" {
+		return goSymbols(filename, content)
+	}
+	re, ok := symbolPatterns[lang]
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, m := range re.FindAllStringSubmatch(content, -1) {
+		for _, name := range m[1:] {
+			if name != "" {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// goSymbols returns the types and functions declared in Go source, except
+// init functions; methods are named Type.Method. Files that fail to parse
+// yield no symbols.
+func goSymbols(filename, content string) []string {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Name.Name == "init" && d.Recv == nil {
+				continue
+			}
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = receiverType(d.Recv.List[0].Type) + "." + name
+			}
+			names = append(names, name)
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				names = append(names, spec.(*ast.TypeSpec).Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+// receiverType returns the type name of a method receiver, without pointer
+// or type parameters.
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
+
+// renderMap renders the file tree with up to limit symbols per file.
+func renderMap(files []mapFile, limit int) string {
+	var sb strings.Builder
+	var prev []string
+	for _, f := range files {
+		parts := strings.Split(f.path, "/")
+		dirs := parts[:len(parts)-1]
+		common := 0
+		for common < len(dirs) && common < len(prev) && dirs[common] == prev[common] {
+			common++
+		}
+		for i := common; i < len(dirs); i++ {
+			fmt.Fprintf(&sb, "%s%s/\n", strings.Repeat("  ", i), dirs[i])
+		}
+		prev = dirs
+
+		fmt.Fprintf(&sb, "%s%s  [", strings.Repeat("  ", len(dirs)), parts[len(parts)-1])
+		if f.lang != "" {
+			sb.WriteString(f.lang + ", ")
+		}
+		sb.WriteString(formatSize(f.size) + "]")
+		if limit > 0 && len(f.symbols) > 0 {
+			shown := f.symbols
+			if len(shown) > limit {
+				shown = shown[:limit]
+			}
+			sb.WriteString(" " + strings.Join(shown, ", "))
+			if more := len(f.symbols) - len(shown); more > 0 {
+				fmt.Fprintf(&sb, ", +%d more", more)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// renderDirs renders only directories with their file counts and total size.
+func renderDirs(files []mapFile) string {
+	type dirStat struct {
+		files int
+		size  int64
+	}
+	stats := map[string]*dirStat{}
+	var dirs []string
+	for _, f := range files {
+		dir := path.Dir(f.path)
+		s, ok := stats[dir]
+		if !ok {
+			s = &dirStat{}
+			stats[dir] = s
+			dirs = append(dirs, dir)
+		}
+		s.files++
+		s.size += f.size
+	}
+	sort.Strings(dirs)
+
+	var sb strings.Builder
+	for _, dir := range dirs {
+		s := stats[dir]
+		noun := "files"
+		if s.files == 1 {
+			noun = "file"
+		}
+		fmt.Fprintf(&sb, "%s/  [%d %s, %s]\n", dir, s.files, noun, formatSize(s.size))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// truncateLines cuts text to at most maxChars at a line boundary and notes
+// how many lines were dropped.
+func truncateLines(text string, maxChars int) string {
+	lines := strings.Split(text, "\n")
+	used := 0
+	for i, line := range lines {
+		used += len(line) + 1
+		if used > maxChars {
+			return strings.Join(lines[:i], "\n") + fmt.Sprintf("\n… %d more directories", len(lines)-i)
+		}
+	}
+	return text
+}
+
+// formatSize renders a byte count as B, KB or MB.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%dB", n)
+}
@@ -13,82 +13,123 @@ import (
 type FileEntry struct {
 	Path    string
 	Content string
+	Outline bool // Content is a Go outline rather than the full file
 }
 
-// Scan collects project files based on config patterns.
+// maxCandidates bounds the number of files read when collecting candidates.
+const maxCandidates = 10000
+
+// Scan collects project files based on config patterns, in walk order, up
+// to the max_files and max_tokens budgets.
 func Scan(cfg *config.ProjectCtxConfig) ([]FileEntry, error) {
-	maxSize, err := parseSize(cfg.MaxFileSize)
+	files, err := Collect(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("parsing max_file_size: %w", err)
+		return nil, err
 	}
+	return Select(files, nil, cfg.MaxFiles, cfg.MaxTokens), nil
+}
 
+// Collect returns every file eligible for the project context. Files ignored
+// by .vcodingignore, and unless disabled by .gitignore and .git/info/exclude,
+// are skipped. With tracked_only, only files tracked by git are considered.
+func Collect(cfg *config.ProjectCtxConfig) ([]FileEntry, error) {
 	var entries []FileEntry
-	seen := map[string]bool{}
+	err := walkEligible(cfg, func(rel string, info os.FileInfo) bool {
+		content, err := os.ReadFile(filepath.FromSlash(rel))
+		if err != nil {
+			return true
+		}
+		entries = append(entries, FileEntry{Path: rel, Content: string(content)})
+		return len(entries) < maxCandidates
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Glob returns the files eligible under cfg that match pattern, which takes
+// the place of include_patterns and uses the same gitignore syntax ("**"
+// included). Ignore files, exclude_patterns, max_file_size and the scope
+// still apply, and the result is bounded by max_files and max_tokens.
+func Glob(cfg *config.ProjectCtxConfig, pattern string) ([]FileEntry, error) {
+	globCfg := *cfg
+	globCfg.IncludePatterns = []string{pattern}
+	files, err := Collect(&globCfg)
+	if err != nil {
+		return nil, err
+	}
+	return Select(files, nil, cfg.MaxFiles, cfg.MaxTokens), nil
+}
 
-	err = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+// walkEligible calls fn with the slash-separated path of every file
+// eligible for the project context, in walk order, until fn returns false.
+func walkEligible(cfg *config.ProjectCtxConfig, fn func(rel string, info os.FileInfo) bool) error {
+	filter, err := newPathFilter(cfg, cfg.TrackedOnly)
+	if err != nil {
+		return err
+	}
+	if cfg.TrackedOnly {
+		return walkTracked(filter, fn)
+	}
+
+	return filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // skip unreadable paths
 		}
+		rel := filepath.ToSlash(path)
 		if info.IsDir() {
-			// Check if this directory matches an exclude pattern
-			dirName := path + "/"
-			for _, pat := range cfg.ExcludePatterns {
-				if strings.HasPrefix(dirName, pat) || strings.Contains(dirName, "/"+pat) {
-					return filepath.SkipDir
-				}
-			}
-			// Skip hidden directories except root "."
-			if path != "." && strings.HasPrefix(info.Name(), ".") {
+			if path != "." && filter.skipDir(rel) != "" {
 				return filepath.SkipDir
 			}
 			return nil
 		}
-
-		if seen[path] {
+		if filter.skipFile(rel, info.Size()) != "" {
 			return nil
 		}
-
-		// Check exclude patterns
-		for _, pat := range cfg.ExcludePatterns {
-			if strings.Contains(path, pat) {
-				return nil
-			}
+		if !fn(rel, info) {
+			return filepath.SkipAll
 		}
+		return nil
+	})
+}
 
-		// Check include patterns
-		matched := false
-		for _, pat := range cfg.IncludePatterns {
-			ok, _ := filepath.Match(pat, filepath.Base(path))
-			if ok {
-				matched = true
-				break
-			}
-		}
-		if !matched {
-			return nil
-		}
+// walkTracked is walkEligible for the files tracked by git. Tracked files
+// are not subject to .gitignore, but .vcodingignore applies.
+func walkTracked(filter *pathFilter, fn func(rel string, info os.FileInfo) bool) error {
+	paths, err := TrackedFiles()
+	if err != nil {
+		return err
+	}
 
-		if info.Size() > maxSize {
-			return nil
+	seen := map[string]bool{}
+	for _, p := range paths {
+		if seen[p] || skippedDirs(filter, p) != "" {
+			continue
 		}
-
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return nil
+		seen[p] = true
+		info, err := os.Stat(filepath.FromSlash(p))
+		if err != nil || info.IsDir() || filter.skipFile(p, info.Size()) != "" {
+			continue
 		}
+		if !fn(p, info) {
+			break
+		}
+	}
+	return nil
+}
 
-		seen[path] = true
-		entries = append(entries, FileEntry{Path: path, Content: string(content)})
-
-		if len(entries) >= cfg.MaxFiles {
-			return filepath.SkipAll
+// skippedDirs returns why the walk would not reach p because one of its
+// parent directories is skipped, or "".
+func skippedDirs(filter *pathFilter, p string) string {
+	parts := strings.Split(p, "/")
+	for i := 1; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		if reason := filter.skipDir(dir); reason != "" {
+			return dir + "/: " + reason
 		}
-		return nil
-	})
-	if err != nil {
-		return nil, err
 	}
-	return entries, nil
+	return ""
 }
 
 // FormatContext formats project files into a markdown string for LLM context.
@@ -99,12 +140,18 @@ func FormatContext(entries []FileEntry) string {
 	var sb strings.Builder
 	sb.WriteString("## Project Context\n\n")
 	for _, e := range entries {
+		if e.Outline {
+			sb.WriteString(fmt.Sprintf("### %s\n\n```go\n%s```\n\n", e.Path, e.Content))
+			continue
+		}
 		sb.WriteString(fmt.Sprintf("### %s\n\n```\n%s\n```\n\n", e.Path, e.Content))
 	}
 	return sb.String()
 }
 
-func parseSize(s string) (int64, error) {
+// ParseSize parses a human-readable size such as "50KB" or "1MB" into bytes.
+// An empty string yields the 50KB default.
+func ParseSize(s string) (int64, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return 50 * 1024, nil
@@ -0,0 +1,163 @@
+// Package prompt renders system prompt templates with Go's text/template engine.
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/futureCreator/vcoding/internal/config"
+)
+
+// Data is the value passed to every prompt template.
+//
+// Templates reference it with dot notation, for example:
+//
+//	{{.Ticket.Title}}             ticket title
+//	{{join .Ticket.Labels ", "}}  ticket labels
+//	{{.Run.ID}}                   run identifier
+//	{{.Git.Repo}}                 repository in owner/repo form
+//	{{.Config.Language.Artifacts}} configured artifact language
+//	{{.Step.Name}}                current step name
+//	{{.Vars.audience}}            step variable declared under `vars:` in pipeline YAML
+//
+// Referencing a field or step variable that does not exist is an error.
+type Data struct {
+	Ticket TicketData
+	Run    RunData
+	Config *config.Config
+	Git    GitData
+	Step   StepData
+	Vars   map[string]string
+}
+
+// TicketData describes the input ticket.
+type TicketData struct {
+	Title  string
+	Body   string
+	Ref    string // issue number, spec path or "user-prompt"
+	Mode   string // "pick" | "do" | "ask"
+	Labels []string
+}
+
+// RunData describes the current run.
+type RunData struct {
+	ID  string
+	Dir string
+}
+
+// GitData describes the repository state at run start.
+type GitData struct {
+	Repo   string // owner/repo, empty if unknown
+	Branch string
+	Commit string
+}
+
+// StepData describes the step whose prompt is being rendered.
+type StepData struct {
+	Name   string
+	Model  string
+	Output string
+}
+
+// ForStep returns a copy of d with step-specific fields populated.
+// The returned Vars map is never nil so that missing keys are reported.
+func (d Data) ForStep(step StepData, vars map[string]string) *Data {
+	d.Step = step
+	d.Vars = make(map[string]string, len(vars))
+	for k, v := range vars {
+		d.Vars[k] = v
+	}
+	return &d
+}
+
+// Set is a collection of parsed prompt templates sharing a common set of partials.
+// Partials are referenced from prompts with {{template "name" .}}.
+type Set struct {
+	root  *template.Template
+	names map[string]bool
+}
+
+// New parses prompts and partials (both name → content) into a Set.
+// A syntax error in any template is returned immediately.
+func New(prompts, partials map[string]string) (*Set, error) {
+	root := template.New("").Funcs(funcs).Option("missingkey=error")
+
+	for _, name := range sortedKeys(partials) {
+		if _, err := root.New(name).Parse(partials[name]); err != nil {
+			return nil, fmt.Errorf("parsing prompt partial %q: %w", name, err)
+		}
+	}
+
+	names := make(map[string]bool, len(prompts))
+	for _, name := range sortedKeys(prompts) {
+		if _, ok := partials[name]; ok {
+			return nil, fmt.Errorf("prompt %q conflicts with a partial of the same name", name)
+		}
+		if _, err := root.New(name).Parse(prompts[name]); err != nil {
+			return nil, fmt.Errorf("parsing prompt template %q: %w", name, err)
+		}
+		names[name] = true
+	}
+
+	return &Set{root: root, names: names}, nil
+}
+
+// Has reports whether a prompt template with the given name exists.
+func (s *Set) Has(name string) bool {
+	return s.names[name]
+}
+
+// Render executes the named prompt template with data.
+func (s *Set) Render(name string, data *Data) (string, error) {
+	if !s.names[name] {
+		return "", fmt.Errorf("prompt template %q not found", name)
+	}
+	var buf bytes.Buffer
+	if err := s.root.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("rendering prompt %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+var funcs = template.FuncMap{
+	"join":         strings.Join,
+	"lower":        strings.ToLower,
+	"upper":        strings.ToUpper,
+	"trim":         strings.TrimSpace,
+	"languageName": languageName,
+}
+
+// languageNames maps ISO 639-1 codes to English language names.
+var languageNames = map[string]string{
+	"en": "English",
+	"ko": "Korean",
+	"ja": "Japanese",
+	"zh": "Chinese",
+	"de": "German",
+	"fr": "French",
+	"es": "Spanish",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"it": "Italian",
+}
+
+// languageName returns the English name for a language code,
+// or the code itself when it is not known.
+func languageName(code string) string {
+	if name, ok := languageNames[strings.ToLower(code)]; ok {
+		return name
+	}
+	return code
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,244 @@
+// Package redact detects credentials in content sent to model providers and
+// replaces them with stable placeholders.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/futureCreator/vcoding/internal/config"
+)
+
+// minEntropy is the Shannon entropy, in bits per character, above which a
+// quoted value assigned to a secret-looking name is treated as a secret.
+const minEntropy = 3.5
+
+// rule detects one kind of secret. The secret is submatch group, or the
+// whole match when group is 0.
+type rule struct {
+	name    string
+	re      *regexp.Regexp
+	group   int
+	entropy bool // only values with at least minEntropy count
+	dummies bool // skip documentation placeholders such as "your-api-key-here"
+}
+
+// builtinRules cover common credential formats.
+var builtinRules = []rule{
+	{name: "private-key", re: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{name: "aws-access-key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "aws-secret-key", re: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`), group: 1},
+	{name: "gcp-api-key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{name: "github-token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+	{name: "gitlab-token", re: regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`)},
+	{name: "slack-token", re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{name: "stripe-key", re: regexp.MustCompile(`\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}\b`)},
+	{name: "openai-key", re: regexp.MustCompile(`\bsk-(?:proj-|ant-)?[A-Za-z0-9_\-]{20,}\b`)},
+	{name: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	// .env style lines, also as added or removed lines of a diff.
+	{name: "env-secret", re: regexp.MustCompile(`(?m)^[+-]?\s*(?:export\s+)?[A-Z0-9_]*(?:SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|PRIVATE_?KEY|ACCESS_?KEY|CREDENTIALS?)[A-Z0-9_]*\s*=\s*["']?([^\s"'#$<{][^\s"'#]{7,})`), group: 1, dummies: true},
+	// Quoted high-entropy values assigned to secret-looking names in code or config.
+	{name: "secret-assignment", re: regexp.MustCompile(`(?i)[\w.-]*(?:secret|token|password|passwd|api[_-]?key|access[_-]?key|auth)[\w.-]*["']?\s*(?::=|[:=])\s*["']([A-Za-z0-9+/=_\-.]{16,})["']`), group: 1, entropy: true, dummies: true},
+}
+
+// Finding records one redacted secret. The secret itself is never stored.
+type Finding struct {
+	Input       string `json:"input"`          // ticket:body, project:context, git:diff, ...
+	File        string `json:"file,omitempty"` // project file, for project:context
+	Line        int    `json:"line"`
+	Rule        string `json:"rule"`
+	Placeholder string `json:"placeholder"`
+}
+
+// SecretsError is returned in abort mode when content contains secrets.
+type SecretsError struct {
+	Findings []Finding
+}
+
+func (e *SecretsError) Error() string {
+	var where []string
+	for _, f := range e.Findings {
+		loc := f.Input
+		if f.File != "" {
+			loc = f.File
+		}
+		where = append(where, fmt.Sprintf("%s at %s:%d", f.Rule, loc, f.Line))
+	}
+	return fmt.Sprintf("found %d possible secret(s), aborting (redaction.mode is abort): %s", len(e.Findings), strings.Join(where, ", "))
+}
+
+// Redactor replaces secrets and collects findings. A nil Redactor leaves
+// content unchanged.
+type Redactor struct {
+	rules []rule
+	abort bool
+
+	mu       sync.Mutex
+	findings []Finding
+}
+
+// New returns a redactor for cfg, or nil when redaction is off. User
+// patterns redact their first capturing group, or the whole match.
+func New(cfg config.RedactionConfig) (*Redactor, error) {
+	if cfg.Mode == "off" {
+		return nil, nil
+	}
+	r := &Redactor{rules: append([]rule(nil), builtinRules...), abort: cfg.Mode == "abort"}
+	for i, p := range cfg.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("redaction.patterns[%d]: %w", i, err)
+		}
+		group := 0
+		if re.NumSubexp() > 0 {
+			group = 1
+		}
+		r.rules = append(r.rules, rule{name: fmt.Sprintf("custom-%d", i+1), re: re, group: group})
+	}
+	return r, nil
+}
+
+// span is a secret found in content.
+type span struct {
+	start, end int
+	rule       string
+}
+
+// Redact replaces the secrets in content, which belongs to input (and file,
+// for project files), with placeholders such as [REDACTED:github-token:1a2b3c4d].
+// The same secret always yields the same placeholder. In abort mode, content
+// containing secrets yields a *SecretsError instead.
+func (r *Redactor) Redact(input, file, content string) (string, error) {
+	if r == nil || content == "" {
+		return content, nil
+	}
+	spans := r.scan(content)
+	if len(spans) == 0 {
+		return content, nil
+	}
+
+	var sb strings.Builder
+	var found []Finding
+	last := 0
+	for _, s := range spans {
+		secret := content[s.start:s.end]
+		placeholder := Placeholder(s.rule, secret)
+		found = append(found, Finding{
+			Input:       input,
+			File:        file,
+			Line:        strings.Count(content[:s.start], "\n") + 1,
+			Rule:        s.rule,
+			Placeholder: placeholder,
+		})
+		sb.WriteString(content[last:s.start])
+		sb.WriteString(placeholder)
+		last = s.end
+	}
+	sb.WriteString(content[last:])
+
+	r.mu.Lock()
+	r.findings = append(r.findings, found...)
+	r.mu.Unlock()
+
+	if r.abort {
+		return "", &SecretsError{Findings: found}
+	}
+	return sb.String(), nil
+}
+
+// scan returns the non-overlapping secrets in content, in order. Where
+// matches overlap, the earlier and then the longer one wins.
+func (r *Redactor) scan(content string) []span {
+	var spans []span
+	for _, rl := range r.rules {
+		for _, m := range rl.re.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[2*rl.group], m[2*rl.group+1]
+			if start < 0 {
+				continue
+			}
+			value := content[start:end]
+			if strings.HasPrefix(value, "[REDACTED:") {
+				continue
+			}
+			if (rl.entropy && !looksRandom(value)) || (rl.dummies && dummyRe.MatchString(value)) {
+				continue
+			}
+			spans = append(spans, span{start: start, end: end, rule: rl.name})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	var result []span
+	for _, s := range spans {
+		if len(result) > 0 && s.start < result[len(result)-1].end {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// Findings returns the secrets redacted so far.
+func (r *Redactor) Findings() []Finding {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Finding(nil), r.findings...)
+}
+
+// Placeholder returns the stable replacement for secret.
+func Placeholder(rule, secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return fmt.Sprintf("[REDACTED:%s:%s]", rule, hex.EncodeToString(sum[:4]))
+}
+
+// dummyRe matches values that are documentation placeholders rather than
+// secrets.
+var dummyRe = regexp.MustCompile(`(?i)your|example|placeholder|changeme|dummy|redacted|x{4,}|\*{3,}|\.\.\.`)
+
+var upperSnakeRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// looksRandom reports whether value looks like a generated secret rather
+// than an identifier or prose: it mixes letters and digits and has high
+// entropy.
+func looksRandom(value string) bool {
+	if upperSnakeRe.MatchString(value) {
+		return false // an environment variable name
+	}
+	if !strings.ContainsAny(value, "0123456789") || strings.IndexFunc(value, isLetter) < 0 {
+		return false
+	}
+	return entropy(value) >= minEntropy
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, c := range s {
+		counts[c]++
+	}
+	n := float64(len([]rune(s)))
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -1,6 +1,8 @@
 package run
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -19,26 +21,131 @@ type Run struct {
 
 // Meta holds metadata about a run, persisted to meta.json.
 type Meta struct {
-	StartedAt time.Time    `json:"started_at"`
-	InputMode string       `json:"input_mode"` // "pick" | "do"
-	InputRef  string       `json:"input_ref"`  // issue number or spec path
-	Status    string       `json:"status"`     // "running" | "completed" | "failed"
-	Steps     []StepResult `json:"steps"`
-	TotalCost float64      `json:"total_cost"`
-	Error     string       `json:"error,omitempty"`
-	GitBranch string       `json:"git_branch"`
-	GitCommit string       `json:"git_commit"`
+	StartedAt     time.Time    `json:"started_at"`
+	InputMode     string       `json:"input_mode"` // "pick" | "do"
+	InputRef      string       `json:"input_ref"`  // issue number or spec path
+	Status        string       `json:"status"`     // "running" | "completed" | "failed" | "cancelled"
+	Steps         []StepResult `json:"steps"`
+	TotalCost     float64      `json:"total_cost"`
+	Error         string       `json:"error,omitempty"`
+	CancelledStep string       `json:"cancelled_step,omitempty"` // step running when the run was cancelled
+	GitBranch     string       `json:"git_branch"`
+	GitCommit     string       `json:"git_commit"`
+	DryRun        bool         `json:"dry_run,omitempty"` // requests were rendered, not sent; costs are estimates
+	Scope         string       `json:"scope,omitempty"`   // subdirectory the run was limited to
+	Ticket        *TicketMeta  `json:"ticket,omitempty"`  // tracker metadata of a picked issue
+	// Artifacts records the version history of every file written to the run
+	// directory, keyed by artifact name, oldest version first.
+	Artifacts map[string][]ArtifactVersion `json:"artifacts,omitempty"`
+}
+
+// TicketMeta records the tracker metadata of a picked issue.
+type TicketMeta struct {
+	Tracker string            `json:"tracker"` // "github", "gitlab" or "jira"
+	Labels  []string          `json:"labels,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"` // e.g. "Type": "Bug", "Priority": "High"
+	// Comments lists the comments added to TICKET.md; their bodies are only
+	// kept there, where secrets are redacted.
+	Comments   []TicketComment   `json:"comments,omitempty"`
+	References []TicketReference `json:"references,omitempty"`
+}
+
+// TicketComment records a comment on a picked issue.
+type TicketComment struct {
+	Author    string `json:"author"`
+	CreatedAt string `json:"created_at"`
+	Role      string `json:"role,omitempty"`
+	Reactions int    `json:"reactions,omitempty"`
+}
+
+// TicketReference records an issue or pull request mentioned in a picked issue.
+type TicketReference struct {
+	Ref         string `json:"ref"` // e.g. "#12" or "owner/repo#12"
+	Title       string `json:"title"`
+	State       string `json:"state"`
+	PullRequest bool   `json:"pull_request,omitempty"`
+}
+
+// ArtifactVersion records one write of an artifact.
+type ArtifactVersion struct {
+	Step      string    `json:"step,omitempty"` // producing step, empty for inputs
+	File      string    `json:"file"`           // file holding this version, e.g. "PLAN.md@Plan"
+	SHA256    string    `json:"sha256"`
+	Size      int       `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // StepResult records the outcome of a single step.
 type StepResult struct {
 	Name       string  `json:"name"`
-	Status     string  `json:"status"` // "completed" | "failed" | "skipped"
+	Status     string  `json:"status"` // "completed" | "failed" | "skipped" | "cancelled"
 	Cost       float64 `json:"cost"`
 	TokensIn   int     `json:"tokens_in"`
 	TokensOut  int     `json:"tokens_out"`
+	Attempts   int     `json:"attempts,omitempty"` // executor calls, including corrective re-prompts
 	DurationMS int64   `json:"duration_ms"`
 	Error      string  `json:"error,omitempty"`
+	Partial    string  `json:"partial,omitempty"` // file holding output streamed before cancellation
+
+	ContextFilter *ContextFilterStats `json:"context_filter,omitempty"`
+	Candidates    []CandidateResult   `json:"candidates,omitempty"`
+	Judge         *JudgeResult        `json:"judge,omitempty"`
+	Truncation    *TruncationReport   `json:"truncation,omitempty"`
+}
+
+// CandidateResult records one output of a step run with candidates.
+type CandidateResult struct {
+	File      string  `json:"file"`
+	Model     string  `json:"model"`
+	Cost      float64 `json:"cost"`
+	TokensIn  int     `json:"tokens_in"`
+	TokensOut int     `json:"tokens_out"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// JudgeResult records a judge step's decision between candidates.
+type JudgeResult struct {
+	From   string             `json:"from"`             // artifact the winner was promoted to
+	Winner string             `json:"winner"`           // winning candidate file, or "merged"
+	Scores map[string]float64 `json:"scores,omitempty"` // candidate file → score
+}
+
+// ContextFilterStats records how much of the project context a step's
+// context_filter removed. Token counts are estimates.
+type ContextFilterStats struct {
+	From           string `json:"from"`
+	Section        string `json:"section"`
+	Files          int    `json:"files"`
+	OriginalTokens int    `json:"original_tokens"`
+	FilteredTokens int    `json:"filtered_tokens"`
+	SavedTokens    int    `json:"saved_tokens"`
+	Fallback       bool   `json:"fallback,omitempty"` // no listed file matched; full context kept
+	// Related lists the tests and callers added to the listed files.
+	Related []RelatedFile `json:"related,omitempty"`
+}
+
+// RelatedFile is a file added to a filtered context because it tests or
+// uses one of the listed files.
+type RelatedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"` // e.g. "test of foo.go", "uses project.Collect"
+}
+
+// TruncationReport records how a step's inputs were cut to fit
+// max_context_tokens. Token counts are estimates.
+type TruncationReport struct {
+	Budget int               `json:"budget"` // tokens left for inputs after the system prompt
+	Inputs []InputTruncation `json:"inputs"` // in priority order
+}
+
+// InputTruncation records what was kept of one input.
+type InputTruncation struct {
+	Name         string   `json:"name"`
+	Priority     int      `json:"priority"`
+	Tokens       int      `json:"tokens"`
+	KeptTokens   int      `json:"kept_tokens"`
+	Dropped      bool     `json:"dropped,omitempty"`       // nothing of the input was sent
+	DroppedFiles []string `json:"dropped_files,omitempty"` // files cut from project:context or glob:
 }
 
 // New creates a new run directory under .vcoding/runs/.
@@ -85,6 +192,29 @@ func New(mode, ref, slug, gitBranch, gitCommit string) (*Run, error) {
 	return r, nil
 }
 
+// Open loads an existing run by ID from .vcoding/runs/.
+// The ID "latest" resolves to the most recent run.
+func Open(id string) (*Run, error) {
+	dir := filepath.Join(".vcoding", "runs", id)
+	if id == "latest" {
+		target, err := os.Readlink(dir)
+		if err != nil {
+			return nil, fmt.Errorf("resolving latest run: %w", err)
+		}
+		id = target
+		dir = filepath.Join(".vcoding", "runs", id)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "meta.json"))
+	if err != nil {
+		return nil, fmt.Errorf("reading run %s: %w", id, err)
+	}
+	var meta Meta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parsing meta for run %s: %w", id, err)
+	}
+	return &Run{ID: id, Dir: dir, Meta: meta}, nil
+}
+
 // SaveMeta writes meta.json to the run directory.
 func (r *Run) SaveMeta() error {
 	data, err := json.MarshalIndent(r.Meta, "", "  ")
@@ -115,16 +245,93 @@ func (r *Run) Fail(msg string) error {
 	return r.SaveMeta()
 }
 
+// Cancel marks the run as cancelled while step was running.
+func (r *Run) Cancel(step string) error {
+	r.Meta.Status = "cancelled"
+	r.Meta.CancelledStep = step
+	r.Meta.Error = "cancelled"
+	return r.SaveMeta()
+}
+
 // FilePath returns the absolute path to a file within this run directory.
 func (r *Run) FilePath(name string) string {
 	return filepath.Join(r.Dir, name)
 }
 
-// WriteFile writes content to a named file in the run directory.
+// WriteFile writes content to a named bookkeeping file in the run
+// directory, such as a report or a dry-run request. It is not versioned;
+// use WriteArtifact for files steps read from each other.
 func (r *Run) WriteFile(name, content string) error {
 	return os.WriteFile(r.FilePath(name), []byte(content), 0644)
 }
 
+// WriteArtifact writes content to a named file in the run directory and
+// records the write in the artifact lineage. The first version is stored
+// under name. Once an artifact is overwritten, every version is also kept
+// as name@<step> (e.g. PLAN.md@Plan, PLAN.md@Revise) and name always holds
+// the latest content.
+func (r *Run) WriteArtifact(name, step, content string) error {
+	versions := r.Meta.Artifacts[name]
+	sum := sha256.Sum256([]byte(content))
+	v := ArtifactVersion{
+		Step:      step,
+		File:      name,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Size:      len(content),
+		CreatedAt: time.Now(),
+	}
+
+	if len(versions) > 0 {
+		// Preserve the first version before it is overwritten.
+		if first := &versions[0]; first.File == name {
+			file := versionFile(name, first.Step, 1, versions)
+			prev, err := os.ReadFile(r.FilePath(name))
+			if err != nil {
+				return fmt.Errorf("reading previous %s: %w", name, err)
+			}
+			if err := os.WriteFile(r.FilePath(file), prev, 0644); err != nil {
+				return fmt.Errorf("preserving previous %s: %w", name, err)
+			}
+			first.File = file
+		}
+		v.File = versionFile(name, step, len(versions)+1, versions)
+		if err := os.WriteFile(r.FilePath(v.File), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(r.FilePath(name), []byte(content), 0644); err != nil {
+		return err
+	}
+
+	if r.Meta.Artifacts == nil {
+		r.Meta.Artifacts = map[string][]ArtifactVersion{}
+	}
+	r.Meta.Artifacts[name] = append(versions, v)
+	return r.SaveMeta()
+}
+
+// versionFile returns a file name for version n of an artifact that does not
+// collide with any existing version: name@<step>, or name@v<n> for versions
+// written outside a step.
+func versionFile(name, step string, n int, existing []ArtifactVersion) string {
+	if step == "" {
+		return fmt.Sprintf("%s@v%d", name, n)
+	}
+	file := name + "@" + step
+	for _, v := range existing {
+		if v.File == file {
+			return fmt.Sprintf("%s@%s-%d", name, step, n)
+		}
+	}
+	return file
+}
+
+// ArtifactVersions returns the recorded versions of an artifact, oldest first.
+func (r *Run) ArtifactVersions(name string) []ArtifactVersion {
+	return r.Meta.Artifacts[name]
+}
+
 // ReadFile reads a named file from the run directory.
 func (r *Run) ReadFile(name string) (string, error) {
 	data, err := os.ReadFile(r.FilePath(name))
@@ -3,13 +3,20 @@ package source
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/futureCreator/vcoding/internal/github"
 )
 
-// GitHubSource fetches a GitHub issue via the gh CLI.
+// maxReferences bounds the lookups of mentioned issues for one ticket.
+const maxReferences = 10
+
+// GitHubSource fetches a GitHub issue, its comments and the issues it
+// mentions via the gh CLI.
 type GitHubSource struct {
 	IssueNumber string
+	Repo        string               // current repository, "owner/repo"; empty if unknown
+	Comments    github.CommentFilter // selects the comments added to the ticket
 }
 
 func (s *GitHubSource) Fetch(ctx context.Context) (*Input, error) {
@@ -20,15 +27,111 @@ func (s *GitHubSource) Fetch(ctx context.Context) (*Input, error) {
 
 	slug := slugFromTitle(issue.Title)
 
+	labels := make([]string, 0, len(issue.Labels))
+	for _, l := range issue.Labels {
+		labels = append(labels, l.Name)
+	}
+	milestone := ""
+	if issue.Milestone != nil {
+		milestone = issue.Milestone.Title
+	}
+
+	var comments []Comment
+	omitted := 0
+	texts := []string{issue.Body}
+	for _, c := range issue.Comments {
+		if !s.Comments.Keep(c, issue.Author.Login) {
+			omitted++
+			continue
+		}
+		role := ""
+		if c.ByMaintainer() {
+			role = strings.ToLower(c.AuthorAssociation)
+		}
+		comments = append(comments, Comment{
+			Author:    c.Author.Login,
+			CreatedAt: c.CreatedAt,
+			Body:      c.Body,
+			Role:      role,
+			Reactions: c.Reactions(),
+		})
+		texts = append(texts, c.Body)
+	}
+	refs := fetchReferences(ctx, s.Repo, issue.Number, texts)
+
+	fields := []Field{
+		{Name: "Issue", Value: fmt.Sprintf("#%d", issue.Number)},
+		{Name: "State", Value: strings.ToLower(issue.State)},
+		{Name: "Author", Value: "@" + issue.Author.Login},
+		{Name: "Labels", Value: strings.Join(labels, ", ")},
+		{Name: "Milestone", Value: milestone},
+	}
+	if issue.Author.Login == "" {
+		fields[2].Value = ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(formatFields(fields))
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		sb.WriteString("\n" + body + "\n")
+	}
+	if len(refs) > 0 {
+		sb.WriteString("\n## Referenced Issues\n\n")
+		for _, r := range refs {
+			kind := "issue"
+			if r.PullRequest {
+				kind = "pull request"
+			}
+			fmt.Fprintf(&sb, "- %s %s (%s %s)\n", r.Ref, r.Title, r.State, kind)
+		}
+	}
+	if len(comments) > 0 {
+		sb.WriteString("\n" + formatComments("Comments", comments))
+	}
+	if omitted > 0 {
+		noun := "comments"
+		if omitted == 1 {
+			noun = "comment"
+		}
+		fmt.Fprintf(&sb, "\n_%d %s left out by the comment filter._\n", omitted, noun)
+	}
+
 	return &Input{
-		Title: issue.Title,
-		Body:  issue.Body,
-		Slug:  fmt.Sprintf("%s-%s", s.IssueNumber, slug),
-		Mode:  "pick",
-		Ref:   s.IssueNumber,
+		Title:      issue.Title,
+		Body:       strings.TrimSpace(sb.String()),
+		Slug:       fmt.Sprintf("%s-%s", s.IssueNumber, slug),
+		Mode:       "pick",
+		Ref:        s.IssueNumber,
+		Labels:     labels,
+		Tracker:    "github",
+		Fields:     fields,
+		Comments:   comments,
+		References: refs,
 	}, nil
 }
 
+// fetchReferences looks up the issues and pull requests mentioned in texts,
+// other than issue number of repo itself. Mentions that cannot be looked up,
+// such as "#1" in prose or issues of private repositories, are left out.
+func fetchReferences(ctx context.Context, repo string, number int, texts []string) []Reference {
+	var refs []Reference
+	lookups := 0
+	for _, r := range github.FindReferences(repo, texts...) {
+		if r.Repo == "" && r.Number == number {
+			continue
+		}
+		if lookups == maxReferences {
+			break
+		}
+		lookups++
+		if err := github.FetchReference(ctx, &r); err != nil {
+			continue
+		}
+		refs = append(refs, Reference{Ref: r.String(), Title: r.Title, State: r.State, PullRequest: r.PullRequest})
+	}
+	return refs
+}
+
 func slugFromTitle(title string) string {
 	var sb []byte
 	for i := 0; i < len(title); i++ {
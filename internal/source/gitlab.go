@@ -0,0 +1,47 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/futureCreator/vcoding/internal/gitlab"
+)
+
+// GitLabSource fetches a GitLab issue and its notes via the REST API.
+type GitLabSource struct {
+	Client  *gitlab.Client
+	Project string // project path, e.g. "group/repo"
+	IID     string // issue number within the project
+}
+
+func (s *GitLabSource) Fetch(ctx context.Context) (*Input, error) {
+	issue, err := s.Client.FetchIssue(ctx, s.Project, s.IID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching GitLab issue %s#%s: %w", s.Project, s.IID, err)
+	}
+	notes, err := s.Client.FetchNotes(ctx, s.Project, s.IID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching notes of GitLab issue %s#%s: %w", s.Project, s.IID, err)
+	}
+
+	comments := make([]Comment, 0, len(notes))
+	for _, n := range notes {
+		comments = append(comments, Comment{Author: n.Author.Username, CreatedAt: n.CreatedAt, Body: n.Body})
+	}
+	body := strings.TrimSpace(issue.Description)
+	if len(comments) > 0 {
+		body = strings.TrimSpace(body + "\n\n" + formatComments("Notes", comments))
+	}
+
+	return &Input{
+		Title:    issue.Title,
+		Body:     body,
+		Slug:     fmt.Sprintf("%s-%s", s.IID, slugFromTitle(issue.Title)),
+		Mode:     "pick",
+		Ref:      "gitlab:" + s.IID,
+		Labels:   issue.Labels,
+		Tracker:  "gitlab",
+		Comments: comments,
+	}, nil
+}
@@ -0,0 +1,116 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/futureCreator/vcoding/internal/gitlab"
+)
+
+// newGitLabServer serves issue 7 of group/repo with notes on two pages, the
+// second holding a system note.
+func newGitLabServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	note := func(user, body string, system bool) map[string]any {
+		return map[string]any{"author": map[string]string{"username": user}, "body": body, "created_at": "2026-01-01T00:00:00Z", "system": system}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret" {
+			t.Errorf("PRIVATE-TOKEN = %q, want %q", got, "secret")
+		}
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Frepo/issues/7":
+			json.NewEncoder(w).Encode(map[string]any{
+				"iid":         7,
+				"title":       "Fix login",
+				"description": "Login fails.",
+				"labels":      []string{"bug"},
+			})
+		case "/api/v4/projects/group%2Frepo/issues/7/notes":
+			switch r.URL.Query().Get("page") {
+			case "1":
+				w.Header().Set("X-Next-Page", "2")
+				json.NewEncoder(w).Encode([]any{note("alice", "First note", false)})
+			case "2":
+				json.NewEncoder(w).Encode([]any{
+					note("bot", "changed the description", true),
+					note("bob", "Second note", false),
+				})
+			default:
+				t.Errorf("unexpected notes page %q", r.URL.Query().Get("page"))
+			}
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGitLabSourceFetch(t *testing.T) {
+	srv := newGitLabServer(t)
+	src := &GitLabSource{
+		Client:  &gitlab.Client{BaseURL: srv.URL, Token: "secret", HTTPClient: srv.Client()},
+		Project: "group/repo",
+		IID:     "7",
+	}
+	input, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	if input.Title != "Fix login" || input.Ref != "gitlab:7" || input.Tracker != "gitlab" {
+		t.Errorf("got title %q, ref %q, tracker %q", input.Title, input.Ref, input.Tracker)
+	}
+	if len(input.Comments) != 2 || input.Comments[0].Author != "alice" || input.Comments[1].Author != "bob" {
+		t.Fatalf("comments = %+v, want alice and bob (notes of both pages, system note left out)", input.Comments)
+	}
+	for _, want := range []string{"Login fails.", "## Notes", "### @alice", "First note", "### @bob", "Second note"} {
+		if !strings.Contains(input.Body, want) {
+			t.Errorf("body does not contain %q:\n%s", want, input.Body)
+		}
+	}
+	if strings.Contains(input.Body, "changed the description") {
+		t.Errorf("body contains a system note:\n%s", input.Body)
+	}
+}
+
+func TestGitLabSourceFetchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"message", http.StatusNotFound, `{"message":"404 Project Not Found"}`, "404 Project Not Found"},
+		{"error", http.StatusUnauthorized, `{"error":"invalid_token"}`, "invalid_token"},
+		{"plain", http.StatusBadGateway, `upstream down`, "upstream down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			src := &GitLabSource{
+				Client:  &gitlab.Client{BaseURL: srv.URL, HTTPClient: srv.Client()},
+				Project: "group/repo",
+				IID:     "7",
+			}
+			_, err := src.Fetch(context.Background())
+			if err == nil {
+				t.Fatal("Fetch succeeded, want error")
+			}
+			if !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), http.StatusText(tt.status)) {
+				t.Errorf("error = %q, want it to contain %q and the status", err, tt.want)
+			}
+		})
+	}
+}
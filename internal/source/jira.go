@@ -0,0 +1,57 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/futureCreator/vcoding/internal/jira"
+)
+
+// JiraSource fetches a Jira issue via the REST API.
+type JiraSource struct {
+	Client *jira.Client
+	Key    string // issue key, e.g. "PROJ-123"
+	// AcceptanceCriteriaField is the custom field holding acceptance
+	// criteria, e.g. "customfield_10100"; empty if there is none.
+	AcceptanceCriteriaField string
+}
+
+func (s *JiraSource) Fetch(ctx context.Context) (*Input, error) {
+	issue, err := s.Client.FetchIssue(ctx, s.Key, s.AcceptanceCriteriaField)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Jira issue %s: %w", s.Key, err)
+	}
+	key := issue.Key
+	if key == "" {
+		key = s.Key
+	}
+
+	fields := []Field{
+		{Name: "Key", Value: key},
+		{Name: "Type", Value: issue.Type},
+		{Name: "Priority", Value: issue.Priority},
+		{Name: "Status", Value: issue.Status},
+		{Name: "Labels", Value: strings.Join(issue.Labels, ", ")},
+	}
+
+	var sb strings.Builder
+	sb.WriteString(formatFields(fields))
+	if issue.Description != "" {
+		sb.WriteString("\n" + issue.Description + "\n")
+	}
+	if issue.AcceptanceCriteria != "" {
+		sb.WriteString("\n## Acceptance Criteria\n\n" + issue.AcceptanceCriteria + "\n")
+	}
+
+	return &Input{
+		Title:   issue.Summary,
+		Body:    strings.TrimSpace(sb.String()),
+		Slug:    fmt.Sprintf("%s-%s", strings.ToLower(key), slugFromTitle(issue.Summary)),
+		Mode:    "pick",
+		Ref:     key,
+		Labels:  issue.Labels,
+		Tracker: "jira",
+		Fields:  fields,
+	}, nil
+}
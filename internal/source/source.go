@@ -1,14 +1,81 @@
 package source
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
 // Input is the normalized input passed to the pipeline.
 type Input struct {
-	Title string
-	Body  string
-	Slug  string
-	Mode  string // "pick" | "do"
-	Ref   string // issue number or file path
+	Title  string
+	Body   string
+	Slug   string
+	Mode   string // "pick" | "do"
+	Ref    string // issue number or file path
+	Labels []string
+	// Tracker is the issue tracker of a picked issue ("github", "gitlab",
+	// "jira"), empty for spec files and messages.
+	Tracker string
+	// Fields holds tracker metadata such as issue type and priority, in
+	// display order.
+	Fields []Field
+	// Comments and References hold the discussion of a picked issue and the
+	// issues it mentions; both are also rendered into Body.
+	Comments   []Comment
+	References []Reference
+}
+
+// Comment is a comment on a picked issue.
+type Comment struct {
+	Author    string
+	CreatedAt string
+	Body      string
+	Role      string // e.g. "member", empty if unknown
+	Reactions int
+}
+
+// Reference is an issue or pull request mentioned in a picked issue.
+type Reference struct {
+	Ref         string // e.g. "#12" or "owner/repo#12"
+	Title       string
+	State       string
+	PullRequest bool
+}
+
+// Field is a named piece of issue metadata.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// formatFields renders fields as a markdown list for the top of a ticket
+// body, skipping empty values.
+func formatFields(fields []Field) string {
+	var sb strings.Builder
+	for _, f := range fields {
+		if f.Value != "" {
+			fmt.Fprintf(&sb, "- **%s:** %s\n", f.Name, f.Value)
+		}
+	}
+	return sb.String()
+}
+
+// formatComments renders comments as a markdown section under heading.
+func formatComments(heading string, comments []Comment) string {
+	var sb strings.Builder
+	sb.WriteString("## " + heading + "\n")
+	for _, c := range comments {
+		details := []string{c.CreatedAt}
+		if c.Role != "" {
+			details = append(details, c.Role)
+		}
+		if c.Reactions > 0 {
+			details = append(details, fmt.Sprintf("%d reactions", c.Reactions))
+		}
+		fmt.Fprintf(&sb, "\n### @%s (%s)\n\n%s\n", c.Author, strings.Join(details, ", "), strings.TrimSpace(c.Body))
+	}
+	return sb.String()
 }
 
 // Source fetches input from an external source and normalizes it.
@@ -1,12 +1,159 @@
 // Package types holds shared data structures used across packages.
 package types
 
+import (
+	"fmt"
+	"regexp"
+)
+
 // Step is a single unit of work in a pipeline.
 type Step struct {
-	Name           string   `yaml:"name"`
-	Executor       string   `yaml:"executor"`
-	Model          string   `yaml:"model,omitempty"`
-	PromptTemplate string   `yaml:"prompt_template,omitempty"`
-	Input          []string `yaml:"input"`
-	Output         string   `yaml:"output,omitempty"`
+	Name           string            `yaml:"name"`
+	Executor       string            `yaml:"executor"`
+	Model          string            `yaml:"model,omitempty"`
+	PromptTemplate string            `yaml:"prompt_template,omitempty"`
+	Input          []string          `yaml:"input"`
+	Output         string            `yaml:"output,omitempty"`
+	Vars           map[string]string `yaml:"vars,omitempty"` // exposed to the prompt template as .Vars
+	Hooks          Hooks             `yaml:"hooks,omitempty"`
+	Expect         *Expect           `yaml:"expect,omitempty"`
+	ContextFilter  *ContextFilter    `yaml:"context_filter,omitempty"`
+	Candidates     *Candidates       `yaml:"candidates,omitempty"`
+	Judge          *Judge            `yaml:"judge,omitempty"`
+	Priorities     map[string]int    `yaml:"priorities,omitempty"` // input → truncation priority, higher is kept first
+}
+
+// Candidates runs a step several times to produce alternative outputs
+// (<name>.candidate-<n><ext>) for a later judge step to choose from.
+type Candidates struct {
+	Count  int      `yaml:"count,omitempty"`  // number of candidates, default len(Models)
+	Models []string `yaml:"models,omitempty"` // models used in turn, default the step's model
+	Seed   *int     `yaml:"seed,omitempty"`   // candidate n is requested with seed+n-1
+}
+
+// N returns the number of candidates to produce.
+func (c *Candidates) N() int {
+	if c.Count == 0 {
+		return len(c.Models)
+	}
+	return c.Count
+}
+
+// Validate checks that at least two candidates are requested.
+func (c *Candidates) Validate() error {
+	if c.Count < 0 {
+		return fmt.Errorf("candidates.count must not be negative")
+	}
+	if c.N() < 2 {
+		return fmt.Errorf("candidates.count must be at least 2 (or list at least 2 models)")
+	}
+	return nil
+}
+
+// Judge makes a step choose between the candidates of an earlier step's
+// output. The winning candidate (or a merge of candidates) is promoted to From.
+type Judge struct {
+	From string `yaml:"from"` // artifact whose candidates are judged, e.g. PLAN.md
+}
+
+// Validate checks that the judge names the artifact it decides on.
+func (j *Judge) Validate() error {
+	if j.From == "" {
+		return fmt.Errorf("judge.from is required")
+	}
+	return nil
+}
+
+// ContextFilter narrows the project context given to a step down to the
+// files listed in a section of an earlier artifact.
+type ContextFilter struct {
+	From           string `yaml:"from"`                      // artifact listing the files, e.g. PLAN.md
+	Section        string `yaml:"section,omitempty"`         // heading of the file list, default "Files to Change"
+	IncludeTests   bool   `yaml:"include_tests,omitempty"`   // also add test files of the listed files
+	IncludeCallers bool   `yaml:"include_callers,omitempty"` // also add Go files using the listed files' exported identifiers
+	RelatedTokens  int    `yaml:"related_tokens,omitempty"`  // budget for added tests and callers, default 10000
+}
+
+// Validate checks that the filter names its source artifact.
+func (f *ContextFilter) Validate() error {
+	if f.From == "" {
+		return fmt.Errorf("context_filter.from is required")
+	}
+	if f.RelatedTokens < 0 {
+		return fmt.Errorf("context_filter.related_tokens must not be negative")
+	}
+	return nil
+}
+
+// SectionName returns the configured section heading or the default.
+func (f *ContextFilter) SectionName() string {
+	if f.Section == "" {
+		return "Files to Change"
+	}
+	return f.Section
+}
+
+// Hooks lists shell commands run around pipeline steps.
+// In config they apply to every step (pre_step, post_step) and to the run as a
+// whole (on_success, on_failure); on a step they apply to that step only.
+type Hooks struct {
+	PreStep   []string `yaml:"pre_step,omitempty"`
+	PostStep  []string `yaml:"post_step,omitempty"`
+	OnSuccess []string `yaml:"on_success,omitempty"`
+	OnFailure []string `yaml:"on_failure,omitempty"`
+	// OnPreStepError decides what a failing pre_step hook does to its step:
+	// "fail" (default) fails the step and the run, "skip" skips the step.
+	OnPreStepError string `yaml:"on_pre_step_error,omitempty"`
+}
+
+// Validate checks hook options that cannot be verified by YAML decoding.
+func (h Hooks) Validate() error {
+	switch h.OnPreStepError {
+	case "", "fail", "skip":
+		return nil
+	}
+	return fmt.Errorf("hooks.on_pre_step_error must be \"fail\" or \"skip\", got %q", h.OnPreStepError)
+}
+
+// Expect declares rules a step's output must satisfy. A non-conforming output
+// is sent back to the model with the validation errors up to Retries times.
+type Expect struct {
+	Headings  []string `yaml:"headings,omitempty"`   // markdown headings that must be present
+	Patterns  []string `yaml:"patterns,omitempty"`   // regular expressions that must match
+	MinLength int      `yaml:"min_length,omitempty"` // in characters
+	MaxLength int      `yaml:"max_length,omitempty"` // in characters, 0 = unlimited
+	FileList  bool     `yaml:"file_list,omitempty"`  // the file list section must list at least one file
+	JSON      bool     `yaml:"json,omitempty"`       // output must be valid JSON
+	Retries   *int     `yaml:"retries,omitempty"`    // corrective re-prompts, default 1
+	// FileListSection is the heading of the section checked by FileList.
+	// Default: the section of the context_filter that reads the output, or
+	// "Files to Change".
+	FileListSection string `yaml:"file_list_section,omitempty"`
+}
+
+// MaxRetries returns the number of corrective re-prompts allowed.
+func (x *Expect) MaxRetries() int {
+	if x == nil || x.Retries == nil {
+		return 1
+	}
+	return *x.Retries
+}
+
+// Validate checks that the rules themselves are well-formed.
+func (x *Expect) Validate() error {
+	for _, p := range x.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("expect.patterns: %w", err)
+		}
+	}
+	if x.MaxLength > 0 && x.MinLength > x.MaxLength {
+		return fmt.Errorf("expect.min_length (%d) exceeds max_length (%d)", x.MinLength, x.MaxLength)
+	}
+	if x.Retries != nil && *x.Retries < 0 {
+		return fmt.Errorf("expect.retries must not be negative")
+	}
+	if x.FileListSection != "" && !x.FileList {
+		return fmt.Errorf("expect.file_list_section requires file_list: true")
+	}
+	return nil
 }